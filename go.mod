@@ -3,7 +3,9 @@ module scp_with_conflicts
 go 1.24.6
 
 require (
+	github.com/klauspost/compress v1.18.0
 	github.com/lanl/highs v1.0.3
+	github.com/ulikunitz/xz v0.5.15
 	gonum.org/v1/gonum v0.16.0
 	gopkg.in/dnaeon/go-priorityqueue.v1 v1.1.1
 )
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"scp_with_conflicts/src/scpcs_solve/scpcs"
+)
+
+func main() {
+	var inPath, outPath string
+	modelFormat := scpcs.ModelMPS
+
+	flag.StringVar(&inPath, "inst", "", "The instance file, - for the standard input")
+	flag.StringVar(&outPath, "out", "-", "The output model file, - for the standard output")
+	loadOpts := scpcs.InstanceFlags(flag.CommandLine)
+	flag.Func("to", "the model format: mps or lp (default mps)", func(s string) (err error) {
+		modelFormat, err = scpcs.ParseModelFormat(s)
+		return
+	})
+
+	flag.Parse()
+
+	if inPath == "" {
+		fmt.Fprintln(os.Stderr, "Must specify the instance file")
+		os.Exit(1)
+	}
+
+	inst, err := scpcs.LoadInstance(inPath, *loadOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error for instance \"%v\": %v\n", inPath, err)
+		os.Exit(1)
+	}
+
+	out := os.Stdout
+	if outPath != "-" {
+		out, err = os.Create(outPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	err = inst.ExportModel(out, modelFormat)
+	if out != os.Stdout {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while writing \"%v\": %v\n", outPath, err)
+		// Only a partial regular file is removed, not a device or a pipe.
+		if fi, statErr := os.Stat(outPath); out != os.Stdout && statErr == nil && fi.Mode().IsRegular() {
+			os.Remove(outPath)
+		}
+		os.Exit(1)
+	}
+}
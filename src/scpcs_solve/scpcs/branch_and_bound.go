@@ -3,31 +3,13 @@ package scpcs
 import (
 	"fmt"
 	"math"
+	"runtime"
 	"slices"
 
-	"github.com/lanl/highs"
 	"gonum.org/v1/gonum/mat"
 )
 
-const (
-	eps            = 1e-8
-	bbTreeChildren = 6
-)
-
-func cloneLp(lp *highs.Model) *highs.Model {
-	return &highs.Model{
-		Maximize:      lp.Maximize,
-		ColCosts:      slices.Clone(lp.ColCosts),
-		Offset:        lp.Offset,
-		ColLower:      slices.Clone(lp.ColLower),
-		ColUpper:      slices.Clone(lp.ColUpper),
-		RowLower:      slices.Clone(lp.RowLower),
-		RowUpper:      slices.Clone(lp.RowUpper),
-		ConstMatrix:   slices.Clone(lp.ConstMatrix),
-		HessianMatrix: slices.Clone(lp.HessianMatrix),
-		VarTypes:      slices.Clone(lp.VarTypes),
-	}
-}
+const eps = 1e-8
 
 func almostEqual(a, b float64) bool {
 	return math.Abs(a-b) < eps
@@ -40,46 +22,48 @@ func nodeComparator(x, y *Node) int {
 	return -1
 }
 
-func (inst *Instance) fixSubsetInPartialSol(partialSol *Node, include []bool) *Node {
+// fixSubsetInPartialSol creates the child of a restored node applying the
+// fixings.
+func (inst *Instance) fixSubsetInPartialSol(partialSol *Node, fixings []fixing) *Node {
 	newPartialSol := &Node{
-		FixedSubsets: partialSol.FixedSubsets + len(include),
-		PrimalSolution: &Solution{
-			Subsets: mat.NewVecDense(
-				inst.NumSubsets,
-				slices.Clone(partialSol.PrimalSolution.Subsets.RawVector().Data),
-			),
-			TotalCost: partialSol.PrimalSolution.TotalCost,
-		},
+		FixedSubsets: partialSol.FixedSubsets + len(fixings),
+		parent:       partialSol,
+		fixed:        make([]int32, len(fixings)),
+		fixings:      newBitset(len(fixings)),
+		fixedCost:    partialSol.fixedCost,
 	}
-	for i, flag := range include {
-		if flag {
-			newPartialSol.PrimalSolution.Subsets.SetVec(partialSol.FixedSubsets+i, 1)
-			newPartialSol.PrimalSolution.TotalCost += inst.Costs.At(partialSol.FixedSubsets+i, 0) +
-				mat.Dot(newPartialSol.PrimalSolution.Subsets, inst.Conflicts.ColView(partialSol.FixedSubsets+i))
+	for k, f := range fixings {
+		newPartialSol.fixed[k] = int32(f.subset)
+		if f.in {
+			newPartialSol.fixings.set(k)
+			newPartialSol.fixedCost += inst.Costs.At(f.subset, 0) +
+				inst.Conflicts.Penalty(f.subset, partialSol.PrimalSolution.Subsets)
+			for _, g := range fixings[:k] {
+				if g.in {
+					newPartialSol.fixedCost += inst.Conflicts.At(g.subset, f.subset)
+				}
+			}
 		}
 	}
 	return newPartialSol
 }
 
+// generateChildren creates the children of a restored node along its branches.
+// The children share the multipliers of their parent until they are evaluated.
 func generateChildren(inst *Instance, node *Node) []*Node {
-	treeChildren := int(math.Min(bbTreeChildren, float64(inst.NumSubsets-node.FixedSubsets+1)))
-	nodes := make([]*Node, 0, treeChildren)
-
-	for i := range treeChildren - 2 {
-		flags := make([]bool, i+1)
-		flags[i] = true
-		nodes = append(nodes, inst.fixSubsetInPartialSol(node, flags))
+	branches := node.branches.fixings()
+	nodes := make([]*Node, 0, len(branches))
+	for _, fixings := range branches {
+		n := inst.fixSubsetInPartialSol(node, fixings)
+		n.lagrangeanMul = node.lagrangeanMul.retain()
+		nodes = append(nodes, n)
 	}
-	flags := make([]bool, treeChildren-1)
-	nodes = append(nodes, inst.fixSubsetInPartialSol(node, flags))
-	flags[len(flags)-1] = true
-	nodes = append(nodes, inst.fixSubsetInPartialSol(node, flags))
 	return nodes
 }
 
 func (inst *Instance) isLagrangianOptimal(sol *Solution, lambda *mat.VecDense) bool {
 	Ax := mat.NewVecDense(inst.NumElements, nil)
-	Ax.MulVec(inst.Subsets, sol.Subsets)
+	inst.Subsets.MulVecTo(Ax, sol.Subsets)
 	for i := range inst.NumElements {
 		if Ax.At(i, 0) < 1 || !almostEqual(0, lambda.At(i, 0)*(1.0-Ax.At(i, 0))) {
 			return false
@@ -88,68 +72,155 @@ func (inst *Instance) isLagrangianOptimal(sol *Solution, lambda *mat.VecDense) b
 	return true
 }
 
-func (inst *Instance) SolveWithLagrangeanRelaxation() (*Solution, error) {
-	lp := inst.defLagrangeanRelaxation()
-	initialNode := &Node{
-		PrimalSolution: &Solution{
-			Subsets: mat.NewVecDense(inst.NumSubsets, nil),
-		},
+// SolveOptions tunes the solvers.
+type SolveOptions struct {
+	// GreedyScore ranks the subsets in the greedy repair of the nodes.
+	GreedyScore GreedyScore
+	// NodeSelection is the order in which the open nodes are explored.
+	NodeSelection NodeSelection
+	// BranchingRule chooses the subsets fixed in the children of a node.
+	BranchingRule BranchingRule
+	// BranchingFactor is the largest number of children of a node, at least
+	// 2. When 0 it is the number of node evaluations run at a time, so that
+	// the children of a node are evaluated together.
+	BranchingFactor int
+	// JumpInterval and JumpGap control the Hybrid node selection: it jumps to
+	// the node with the lowest bound every JumpInterval nodes, and when the
+	// bound of the next node in depth-first order exceeds the lowest by more
+	// than a JumpGap fraction.
+	JumpInterval int
+	JumpGap      float64
+	// Threads bounds the threads used by the solvers, runtime.NumCPU() when 0.
+	Threads int
+	// HighsThreads is the number of threads of each HiGHS solve, 1 when 0.
+	// The branch and bound runs Threads / HighsThreads node evaluations at a
+	// time. HiGHS shares its threads among all the solves of a process, so the
+	// value should not change between them.
+	HighsThreads int
+}
+
+func (opts SolveOptions) threads() int {
+	if opts.Threads <= 0 {
+		return runtime.NumCPU()
+	}
+	return opts.Threads
+}
+
+func (opts SolveOptions) highsThreads() int {
+	return max(opts.HighsThreads, 1)
+}
+
+func (opts SolveOptions) workers() int {
+	return max(opts.threads()/opts.highsThreads(), 1)
+}
+
+func (opts SolveOptions) branchingFactor() int {
+	if opts.BranchingFactor <= 0 {
+		return max(opts.workers(), 2)
+	}
+	return max(opts.BranchingFactor, 2)
+}
+
+func (inst *Instance) SolveWithLagrangeanRelaxation(opts SolveOptions) (*Solution, error) {
+	subproblems := make([]*lagrangeanSubproblem, opts.workers())
+	for w := range subproblems {
+		sp, err := inst.newLagrangeanSubproblem(opts.highsThreads())
+		if err != nil {
+			return nil, err
+		}
+		subproblems[w] = sp
 	}
 
-	bestPrimalSolution := inst.geneticHeuristic(initialNode, 500)
+	initialNode := new(Node)
+	initialNode.restore(inst)
+
+	bestPrimalSolution := inst.geneticHeuristic(initialNode, 500, opts.threads())
 	fmt.Println("Genetic algorithm primal bound:", bestPrimalSolution.TotalCost)
 
-	initialLB, lambda, err := inst.optimizeSubgradient(lp, initialNode)
+	initialLB, err := inst.optimizeSubgradient(subproblems[0], initialNode, 0)
 	if err != nil {
 		return nil, err
 	}
-	if almostEqual(0, (bestPrimalSolution.TotalCost-initialLB.TotalCost)/bestPrimalSolution.TotalCost) {
+	if almostEqual(0, (bestPrimalSolution.TotalCost-initialLB.bound)/bestPrimalSolution.TotalCost) {
 		return bestPrimalSolution, nil
 	}
-	initialNode.DualBound = initialLB.TotalCost
-	initialNode.LagrangeanMul = lambda
+	initialNode.DualBound = initialLB.bound
+	initialNode.lagrangeanMul = newMultipliers(initialLB.lambda).retain()
+	brancher := inst.newBrancher(opts.BranchingRule, opts.branchingFactor())
+	initialNode.branches, err = brancher.branch(subproblems[0], initialNode, initialLB)
+	if err != nil {
+		return nil, err
+	}
+	initialNode.compact()
 
-	nodesDeque := NewStack[*Node]()
+	nodesDeque := newOpenNodes(opts)
 	nodesDeque.Push(initialNode)
 
+	pool := newWorkerPool(len(subproblems))
+	defer pool.close()
+
 	for nodesDeque.Size() > 0 {
 		node := nodesDeque.Pop()
+		node.restore(inst)
 		fmt.Println(node)
 		fmt.Println("Current UB:", bestPrimalSolution.TotalCost)
+		fmt.Println("Global LB:", math.Min(bestPrimalSolution.TotalCost, math.Min(node.DualBound, nodesDeque.lowerBound())))
 		fmt.Println()
 
 		if node.DualBound > bestPrimalSolution.TotalCost {
+			node.discard()
 			continue
 		}
 		if node.PrimalSolution.TotalCost < bestPrimalSolution.TotalCost && inst.isFeasible(node.PrimalSolution.Subsets) {
 			bestPrimalSolution = node.PrimalSolution
+			node.discard()
 			continue
 		}
-		if node.FixedSubsets == inst.NumSubsets {
+		if node.branches.empty() {
+			node.discard()
 			continue
 		}
 
 		children := generateChildren(inst, node)
 
-		errorCh := make(chan error)
-		primalCh := make(chan *Solution)
+		errorCh := make(chan error, len(children))
+		primalCh := make(chan *Solution, len(children))
 		nodesCh := make(chan *Node, len(children))
+		upperBound := bestPrimalSolution.TotalCost
 		for _, n := range children {
-			go func() {
-				dualSol, lambda, err := inst.optimizeSubgradient(cloneLp(lp), n)
+			pool.submit(func(worker int) {
+				n.restore(inst)
+				dual, err := inst.optimizeSubgradient(subproblems[worker], n, 0)
+				n.lagrangeanMul.release()
+				n.lagrangeanMul = nil
 				if err != nil {
 					errorCh <- err
 					return
 				}
-				if inst.isLagrangianOptimal(dualSol, lambda) {
-					primalCh <- dualSol
+				if inst.isLagrangianOptimal(dual.sol, dual.lambda) {
+					primalCh <- &Solution{
+						Subsets:   dual.sol.Subsets,
+						TotalCost: inst.getCost(dual.sol.Subsets),
+					}
 					return
 				}
 
-				n.DualBound = dualSol.TotalCost
-				n.LagrangeanMul = lambda
+				// The subproblem of a child is a restriction of the one of its
+				// parent, whose bound holds for the child as well.
+				n.DualBound = math.Max(dual.bound, node.DualBound)
+				n.lagrangeanMul = newMultipliers(dual.lambda).retain()
+				brancher.observe(n, n.DualBound-node.DualBound)
+
+				if n.DualBound <= upperBound {
+					n.branches, err = brancher.branch(subproblems[worker], n, dual)
+					if err != nil {
+						errorCh <- err
+						return
+					}
+				}
 
-				repairedSol, err := inst.greedyRepair(n)
+				repairedSol, err := inst.greedyRepair(n, opts.GreedyScore)
+				n.compact()
 				if err != nil {
 					if err.Error() == "Infeasible" {
 						errorCh <- fmt.Errorf("Fathom")
@@ -159,12 +230,13 @@ func (inst *Instance) SolveWithLagrangeanRelaxation() (*Solution, error) {
 					return
 				}
 
-				if n.DualBound <= bestPrimalSolution.TotalCost {
+				n.Estimate = repairedSol.TotalCost
+				if n.DualBound <= upperBound {
 					nodesCh <- n
 				}
 
 				primalCh <- repairedSol
-			}()
+			})
 		}
 
 		for range children {
@@ -181,6 +253,7 @@ func (inst *Instance) SolveWithLagrangeanRelaxation() (*Solution, error) {
 		}
 
 		close(nodesCh)
+		node.discard()
 		toPush := make([]*Node, 0, len(children))
 		for n := range nodesCh {
 			toPush = append(toPush, n)
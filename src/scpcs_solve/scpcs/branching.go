@@ -0,0 +1,457 @@
+package scpcs
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	// strongCandidates is the number of subsets evaluated by strong branching
+	// at each node, and strongIterations the number of subgradient iterations
+	// of each evaluation.
+	strongCandidates = 8
+	strongIterations = 5
+	// reliablePseudoCost is the number of observations after which reliability
+	// branching trusts the pseudo-cost of fixing a subset in or out.
+	reliablePseudoCost = 4
+	// minGain keeps a bound increase of zero from cancelling the other one in
+	// the product scoring the candidates of strong and reliability branching.
+	minGain = 1e-6
+)
+
+// BranchingRule chooses the subsets fixed in the children of a node. The
+// children of a node branching on the subsets s1, ..., sk select s1, select s2
+// but not s1, and so on, and the last one selects none of them, unless they
+// are all the subsets that can cover an element. With a single subset s1 the
+// branching is binary: select s1 or not.
+type BranchingRule int
+
+const (
+	// BranchFractional branches on the subsets whose value in the average of
+	// the Lagrangean solutions of the node is the closest to 1/2.
+	BranchFractional BranchingRule = iota
+	// BranchCoverage branches on the subsets with the largest sum of the
+	// multipliers of the elements they cover and the fixed subsets do not.
+	BranchCoverage
+	// BranchReducedCost branches on the subsets with the smallest Lagrangean
+	// cost.
+	BranchReducedCost
+	// BranchConflictDegree branches on the subsets with the most conflicts.
+	BranchConflictDegree
+	// BranchIndex branches on the free subsets in index order.
+	BranchIndex
+	// BranchStrong branches on the subsets whose fixings increase the bound
+	// the most, evaluated with a few subgradient iterations on the most
+	// fractional subsets.
+	BranchStrong
+	// BranchReliability branches like BranchStrong, but takes the bound
+	// increases from the pseudo-costs of the subsets once they are reliable.
+	BranchReliability
+	// BranchElementCandidates branches on the element not covered by the
+	// fixed subsets with the fewest free subsets covering it, selecting each
+	// of them in turn.
+	BranchElementCandidates
+	// BranchElementMultiplier branches like BranchElementCandidates on the
+	// uncovered element with the largest multiplier.
+	BranchElementMultiplier
+	// BranchConflictPair branches on a conflict between two free subsets i and
+	// j that the Lagrangean solutions select together, with the children
+	// selecting not i, i but not j, and both, whatever the branching factor.
+	// It falls back to BranchFractional when there is no such conflict.
+	BranchConflictPair
+)
+
+var branchingRuleNames = []string{
+	BranchFractional:        "fractional",
+	BranchCoverage:          "coverage",
+	BranchReducedCost:       "reduced-cost",
+	BranchConflictDegree:    "conflict-degree",
+	BranchIndex:             "index",
+	BranchStrong:            "strong",
+	BranchReliability:       "reliability",
+	BranchElementCandidates: "element-candidates",
+	BranchElementMultiplier: "element-multiplier",
+	BranchConflictPair:      "conflict-pair",
+}
+
+func (r BranchingRule) String() string {
+	if r < 0 || int(r) >= len(branchingRuleNames) {
+		return fmt.Sprintf("BranchingRule(%d)", int(r))
+	}
+	return branchingRuleNames[r]
+}
+
+func ParseBranchingRule(s string) (BranchingRule, error) {
+	i := slices.Index(branchingRuleNames, s)
+	if i < 0 {
+		return 0, fmt.Errorf("unknown branching rule \"%v\", expected one of %v", s, branchingRuleNames)
+	}
+	return BranchingRule(i), nil
+}
+
+// fixing fixes a subset in or out of the cover.
+type fixing struct {
+	subset int
+	in     bool
+}
+
+// branchKind tells how the children of a node are derived from the subsets of
+// its branching.
+type branchKind uint8
+
+const (
+	// branchPartition creates a child selecting each of the subsets but none
+	// of the previous ones.
+	branchPartition branchKind = iota
+	// branchCompletePartition adds to branchPartition the child selecting
+	// none of the subsets.
+	branchCompletePartition
+	// branchConflict branches on the conflict between the two subsets i and j,
+	// with the children selecting not i, i but not j, and both.
+	branchConflict
+)
+
+// branching is the compact description of the children of a node, kept by the
+// open nodes until their children are created.
+type branching struct {
+	subsets []int32
+	kind    branchKind
+}
+
+func newBranching(subsets []int, kind branchKind) branching {
+	br := branching{subsets: make([]int32, len(subsets)), kind: kind}
+	for k, i := range subsets {
+		br.subsets[k] = int32(i)
+	}
+	return br
+}
+
+// empty reports whether the node has no children.
+func (br branching) empty() bool {
+	return len(br.subsets) == 0
+}
+
+// fixings returns the fixings of each child.
+func (br branching) fixings() [][]fixing {
+	if br.empty() {
+		return nil
+	}
+	if br.kind == branchConflict {
+		i, j := int(br.subsets[0]), int(br.subsets[1])
+		return [][]fixing{
+			{{i, false}},
+			{{i, true}, {j, false}},
+			{{i, true}, {j, true}},
+		}
+	}
+
+	children := make([][]fixing, 0, len(br.subsets)+1)
+	for k, i := range br.subsets {
+		child := make([]fixing, 0, k+1)
+		for _, j := range br.subsets[:k] {
+			child = append(child, fixing{int(j), false})
+		}
+		children = append(children, append(child, fixing{int(i), true}))
+	}
+	if br.kind == branchCompletePartition {
+		child := make([]fixing, 0, len(br.subsets))
+		for _, j := range br.subsets {
+			child = append(child, fixing{int(j), false})
+		}
+		children = append(children, child)
+	}
+	return children
+}
+
+// pseudoCosts records, for every subset, the bound increases observed when it
+// is fixed out of and in the cover. It is safe for concurrent use.
+type pseudoCosts struct {
+	mu     sync.Mutex
+	gains  [2][]float64
+	counts [2][]int
+	total  [2]float64
+	count  [2]int
+}
+
+func newPseudoCosts(numSubsets int) *pseudoCosts {
+	pc := new(pseudoCosts)
+	for d := range pc.gains {
+		pc.gains[d] = make([]float64, numSubsets)
+		pc.counts[d] = make([]int, numSubsets)
+	}
+	return pc
+}
+
+func direction(f fixing) int {
+	if f.in {
+		return 1
+	}
+	return 0
+}
+
+func (pc *pseudoCosts) record(f fixing, gain float64) {
+	d := direction(f)
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.gains[d][f.subset] += gain
+	pc.counts[d][f.subset]++
+	pc.total[d] += gain
+	pc.count[d]++
+}
+
+// gain returns the average bound increase observed for the fixing, and whether
+// it is reliable. A fixing never observed gets the average over all subsets.
+func (pc *pseudoCosts) gain(f fixing) (float64, bool) {
+	d := direction(f)
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if n := pc.counts[d][f.subset]; n > 0 {
+		return pc.gains[d][f.subset] / float64(n), n >= reliablePseudoCost
+	}
+	if pc.count[d] > 0 {
+		return pc.total[d] / float64(pc.count[d]), false
+	}
+	return 0, false
+}
+
+// brancher chooses the branches of the nodes, at most factor children each. It
+// is shared by the workers of the branch and bound.
+type brancher struct {
+	inst        *Instance
+	rule        BranchingRule
+	factor      int
+	pseudoCosts *pseudoCosts
+}
+
+func (inst *Instance) newBrancher(rule BranchingRule, factor int) *brancher {
+	return &brancher{
+		inst:        inst,
+		rule:        rule,
+		factor:      factor,
+		pseudoCosts: newPseudoCosts(inst.NumSubsets),
+	}
+}
+
+// observe records the bound increase of a child over its parent in the
+// pseudo-costs, when they are used by the rule and the child differs from its
+// parent by a single fixing.
+func (b *brancher) observe(child *Node, gain float64) {
+	if b.rule == BranchReliability && len(child.fixed) == 1 {
+		b.pseudoCosts.record(child.lastFixing(), gain)
+	}
+}
+
+// branch chooses the children of a restored and evaluated node. It returns no
+// children when every subset is fixed.
+func (b *brancher) branch(sp *lagrangeanSubproblem, node *Node, res *subgradient) (branching, error) {
+	inst := b.inst
+	rule := b.rule
+	switch rule {
+	case BranchElementCandidates, BranchElementMultiplier:
+		return b.branchOnElement(node, res), nil
+	case BranchConflictPair:
+		if br := inst.branchOnConflict(node, res); !br.empty() {
+			return br, nil
+		}
+		rule = BranchFractional
+	case BranchStrong, BranchReliability:
+		rule = BranchFractional
+	}
+	scores := inst.branchingScores(node, rule, res)
+	free := make([]int, 0)
+	for i := range inst.NumSubsets {
+		if !node.isFixed(i) {
+			free = append(free, i)
+		}
+	}
+	slices.SortStableFunc(free, func(x, y int) int {
+		return cmp.Compare(scores[y], scores[x])
+	})
+
+	switch b.rule {
+	case BranchStrong:
+		err := b.lookahead(sp, node, res, free[:min(len(free), strongCandidates)])
+		if err != nil {
+			return branching{}, err
+		}
+	case BranchReliability:
+		err := b.lookahead(sp, node, res, free)
+		if err != nil {
+			return branching{}, err
+		}
+	}
+	return newBranching(free[:min(len(free), b.factor-1)], branchCompletePartition), nil
+}
+
+// branchOnElement branches on an element not covered by the fixed subsets of a
+// restored node, with a child selecting each free subset covering it, the ones
+// with the smallest Lagrangean cost first, and none of the previous ones. When
+// there are more than the branching factor of them, the last child selects
+// none of the previous ones and leaves the others free. It returns no children
+// when the fixed subsets are a cover.
+func (b *brancher) branchOnElement(node *Node, res *subgradient) branching {
+	inst := b.inst
+	ev := inst.NewEvaluator()
+	ev.Load(node.PrimalSolution.Subsets)
+	freeCovering := func(e int) (count int) {
+		for _, i := range inst.Subsets.Row(e) {
+			if !node.isFixed(i) {
+				count++
+			}
+		}
+		return
+	}
+
+	element, candidates := -1, 0
+	for e := range inst.NumElements {
+		if ev.Coverage(e) > 0 {
+			continue
+		}
+		count := freeCovering(e)
+		if element < 0 {
+			element, candidates = e, count
+			continue
+		}
+		fewer := count < candidates ||
+			count == candidates && res.lambda.AtVec(e) > res.lambda.AtVec(element)
+		larger := res.lambda.AtVec(e) > res.lambda.AtVec(element) ||
+			res.lambda.AtVec(e) == res.lambda.AtVec(element) && count < candidates
+		if b.rule == BranchElementCandidates && fewer || b.rule == BranchElementMultiplier && larger {
+			element, candidates = e, count
+		}
+	}
+	if element < 0 {
+		return branching{}
+	}
+
+	subsets := make([]int, 0, candidates)
+	for _, i := range inst.Subsets.Row(element) {
+		if !node.isFixed(i) {
+			subsets = append(subsets, i)
+		}
+	}
+	scores := inst.branchingScores(node, BranchReducedCost, res)
+	slices.SortStableFunc(subsets, func(x, y int) int {
+		return cmp.Compare(scores[y], scores[x])
+	})
+	if len(subsets) <= b.factor {
+		return newBranching(subsets, branchPartition)
+	}
+	return newBranching(subsets[:b.factor-1], branchCompletePartition)
+}
+
+// branchOnConflict branches on the conflict between two free subsets i and j
+// of a restored node that the last Lagrangean solution selects together or,
+// failing that, with the largest penalty weighted by the least of their values
+// in the average of the Lagrangean solutions. The children select not i, i but
+// not j, and both i and j, paying the penalty. It returns no children when no
+// conflict qualifies.
+func (inst *Instance) branchOnConflict(node *Node, res *subgradient) branching {
+	best, bestScore := -1, 0.0
+	for k, pair := range inst.ConflictsList {
+		i, j := pair[0], pair[1]
+		if node.isFixed(i) || node.isFixed(j) {
+			continue
+		}
+		together := math.Min(res.average.AtVec(i), res.average.AtVec(j))
+		if res.sol.Subsets.AtVec(i) > 0.5 && res.sol.Subsets.AtVec(j) > 0.5 {
+			together = 1
+		}
+		if score := together * inst.Conflicts.At(i, j); score > bestScore {
+			best, bestScore = k, score
+		}
+	}
+	if best < 0 {
+		return branching{}
+	}
+	return newBranching(inst.ConflictsList[best], branchConflict)
+}
+
+// lookahead sorts the candidates by the product of the bound increases of
+// fixing them out and in. Strong branching evaluates the increases of all the
+// candidates; reliability branching takes them from the pseudo-costs, and
+// evaluates only the first strongCandidates candidates whose pseudo-costs are
+// not reliable.
+func (b *brancher) lookahead(sp *lagrangeanSubproblem, node *Node, res *subgradient, candidates []int) error {
+	scores := make(map[int]float64, len(candidates))
+	evaluated := 0
+	for _, i := range candidates {
+		out, in := fixing{i, false}, fixing{i, true}
+		outGain, outReliable := b.pseudoCosts.gain(out)
+		inGain, inReliable := b.pseudoCosts.gain(in)
+		if b.rule == BranchStrong || !(outReliable && inReliable) && evaluated < strongCandidates {
+			evaluated++
+			var err error
+			outGain, err = b.trialGain(sp, node, res, out)
+			if err != nil {
+				return err
+			}
+			inGain, err = b.trialGain(sp, node, res, in)
+			if err != nil {
+				return err
+			}
+		}
+		scores[i] = math.Max(outGain, minGain) * math.Max(inGain, minGain)
+	}
+	slices.SortStableFunc(candidates, func(x, y int) int {
+		return cmp.Compare(scores[y], scores[x])
+	})
+	return nil
+}
+
+// trialGain returns the bound increase of applying the fixing to a restored
+// node, evaluated with a few subgradient iterations from the multipliers of
+// the node, and observes it for the pseudo-costs.
+func (b *brancher) trialGain(sp *lagrangeanSubproblem, node *Node, res *subgradient, f fixing) (float64, error) {
+	child := b.inst.fixSubsetInPartialSol(node, []fixing{f})
+	child.restore(b.inst)
+	child.lagrangeanMul = newMultipliers(res.lambda)
+	trial, err := b.inst.optimizeSubgradient(sp, child, strongIterations)
+	if err != nil {
+		return 0, err
+	}
+	gain := math.Max(trial.bound-node.DualBound, 0)
+	b.observe(child, gain)
+	return gain, nil
+}
+
+// branchingScores scores the subsets by the rule, the higher the better.
+func (inst *Instance) branchingScores(node *Node, rule BranchingRule, res *subgradient) []float64 {
+	scores := make([]float64, inst.NumSubsets)
+	switch rule {
+	case BranchFractional:
+		for i := range scores {
+			scores[i] = -math.Abs(res.average.AtVec(i) - 0.5)
+		}
+	case BranchCoverage:
+		ev := inst.NewEvaluator()
+		ev.Load(node.PrimalSolution.Subsets)
+		for i := range scores {
+			for _, e := range inst.Subsets.Col(i) {
+				if ev.Coverage(e) == 0 {
+					scores[i] += res.lambda.AtVec(e)
+				}
+			}
+		}
+	case BranchReducedCost:
+		prod := mat.NewVecDense(inst.NumSubsets, nil)
+		inst.Subsets.MulTransVecTo(prod, res.lambda)
+		for i := range scores {
+			scores[i] = prod.AtVec(i) - inst.Costs.AtVec(i)
+		}
+	case BranchConflictDegree:
+		for i := range scores {
+			scores[i] = float64(inst.Conflicts.Degree(i))
+		}
+	case BranchIndex:
+		for i := range scores {
+			scores[i] = -float64(i)
+		}
+	}
+	return scores
+}
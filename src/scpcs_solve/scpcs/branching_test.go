@@ -0,0 +1,287 @@
+package scpcs
+
+import (
+	"slices"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// matchingChildren returns the children whose fixings agree with the selection
+// of the subsets in mask.
+func matchingChildren(children [][]fixing, mask uint) (matches []int) {
+	for c, fixings := range children {
+		agrees := true
+		for _, f := range fixings {
+			agrees = agrees && (mask&(1<<f.subset) != 0) == f.in
+		}
+		if agrees {
+			matches = append(matches, c)
+		}
+	}
+	return
+}
+
+// assertPartition checks that every selection of the subsets of the branching
+// agrees with exactly one child, but the selection of none of them when the
+// partition is not complete.
+func assertPartition(t *testing.T, br branching) {
+	t.Helper()
+	children := br.fixings()
+	var all uint
+	for _, i := range br.subsets {
+		all |= 1 << i
+	}
+	for mask := all; ; mask = (mask - 1) & all {
+		want := 1
+		if mask == 0 && br.kind == branchPartition {
+			want = 0
+		}
+		if got := matchingChildren(children, mask); len(got) != want {
+			t.Errorf("%v: selection %b agrees with the children %v", br, mask, got)
+		}
+		if mask == 0 {
+			break
+		}
+	}
+}
+
+func TestBranchingFixings(t *testing.T) {
+	tests := []struct {
+		subsets  []int
+		kind     branchKind
+		children int
+	}{
+		{nil, branchCompletePartition, 0},
+		{[]int{3}, branchCompletePartition, 2},
+		{[]int{3}, branchPartition, 1},
+		{[]int{5, 0, 2}, branchCompletePartition, 4},
+		{[]int{5, 0, 2, 7}, branchPartition, 4},
+		{[]int{4, 1}, branchConflict, 3},
+	}
+	for _, tt := range tests {
+		br := newBranching(tt.subsets, tt.kind)
+		if br.empty() != (tt.children == 0) {
+			t.Errorf("%v: empty() = %v", br, br.empty())
+		}
+		children := br.fixings()
+		if len(children) != tt.children {
+			t.Errorf("%v: got %d children, want %d", br, len(children), tt.children)
+		}
+		if tt.kind == branchConflict {
+			// The children partition the selections of the pair.
+			for _, sel := range []uint{0, 1 << 1, 1 << 4, 1<<1 | 1<<4} {
+				if got := matchingChildren(children, sel); len(got) != 1 {
+					t.Errorf("%v: selection %b agrees with the children %v", br, sel, got)
+				}
+			}
+			continue
+		}
+		if len(tt.subsets) > 0 {
+			assertPartition(t, br)
+		}
+	}
+}
+
+func TestBranchOrder(t *testing.T) {
+	inst := parseTestInstance(t, testInstance, LoadOptions{})
+	res := &subgradient{
+		lambda:  mat.NewVecDense(inst.NumElements, []float64{1, 2, 3, 4}),
+		average: mat.NewVecDense(inst.NumSubsets, []float64{0.9, 0.1, 0.2, 0.3, 0.45, 0.6, 0.5, 0.7, 0.8, 0.15, 0.05, 0.35, 0.5}),
+	}
+
+	tests := []struct {
+		rule   BranchingRule
+		factor int
+		// want are the subsets of the branching, with 0 fixed in and 12 out.
+		want []int
+	}{
+		{BranchIndex, 2, []int{1}},
+		{BranchIndex, 4, []int{1, 2, 3}},
+		{BranchFractional, 3, []int{6, 4}},
+		{BranchConflictDegree, 2, []int{3}},
+		{BranchReducedCost, 3, []int{1, 3}},
+		{BranchCoverage, 4, []int{9, 6, 10}},
+		{BranchIndex, 20, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}},
+	}
+	for _, tt := range tests {
+		b := inst.newBrancher(tt.rule, tt.factor)
+		br, err := b.branch(nil, fixedNode(inst, []int{0}, []int{12}), res)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.rule, err)
+		}
+		got := make([]int, len(br.subsets))
+		for k, i := range br.subsets {
+			got[k] = int(i)
+		}
+		if !slices.Equal(got, tt.want) || br.kind != branchCompletePartition {
+			t.Errorf("%v with factor %d: branched on %v (%v), want %v", tt.rule, tt.factor, got, br.kind, tt.want)
+		}
+		if children := len(br.fixings()); children > tt.factor {
+			t.Errorf("%v with factor %d: got %d children", tt.rule, tt.factor, children)
+		}
+		assertPartition(t, br)
+	}
+
+	all := make([]int, inst.NumSubsets)
+	for i := range all {
+		all[i] = i
+	}
+	br, err := inst.newBrancher(BranchFractional, 2).branch(nil, fixedNode(inst, all[:3], all[3:]), res)
+	if err != nil || !br.empty() {
+		t.Errorf("branched on %v (%v) with every subset fixed", br, err)
+	}
+}
+
+func TestPseudoCosts(t *testing.T) {
+	pc := newPseudoCosts(3)
+	if gain, reliable := pc.gain(fixing{0, true}); gain != 0 || reliable {
+		t.Errorf("gain without observations: got %v %v, want 0 false", gain, reliable)
+	}
+	for k := range reliablePseudoCost {
+		pc.record(fixing{1, true}, float64(2*k))
+		gain, reliable := pc.gain(fixing{1, true})
+		if want := float64(k); gain != want || reliable != (k == reliablePseudoCost-1) {
+			t.Errorf("after %d observations: got %v %v, want %v %v", k+1, gain, reliable, want, k == reliablePseudoCost-1)
+		}
+	}
+	pc.record(fixing{2, true}, 8)
+	if gain, reliable := pc.gain(fixing{0, true}); gain != 4 || reliable {
+		t.Errorf("gain of an unobserved subset: got %v %v, want the average 4 false", gain, reliable)
+	}
+	if gain, reliable := pc.gain(fixing{1, false}); gain != 0 || reliable {
+		t.Errorf("gain of an unobserved direction: got %v %v, want 0 false", gain, reliable)
+	}
+
+	inst := parseTestInstance(t, testInstance, LoadOptions{})
+	for _, rule := range []BranchingRule{BranchReliability, BranchStrong} {
+		b := inst.newBrancher(rule, 2)
+		b.observe(fixedNode(inst, []int{3}, nil), 1)
+		b.observe(fixedNode(inst, []int{3}, []int{4}), 1)
+		// Only the children differing from their parent by a single fixing
+		// are observed, and only by reliability branching.
+		want := 0
+		if rule == BranchReliability {
+			want = 1
+		}
+		if count := b.pseudoCosts.counts[1][3]; count != want {
+			t.Errorf("%v: got %d observations, want %d", rule, count, want)
+		}
+	}
+
+	// Once reliable, the pseudo-costs rank the candidates without evaluating
+	// them, by the product of their gains.
+	b := inst.newBrancher(BranchReliability, 2)
+	gains := map[int][2]float64{1: {1, 1}, 2: {0, 5}, 3: {2, 3}}
+	for i, g := range gains {
+		for range reliablePseudoCost {
+			b.pseudoCosts.record(fixing{i, false}, g[0])
+			b.pseudoCosts.record(fixing{i, true}, g[1])
+		}
+	}
+	candidates := []int{1, 2, 3}
+	if err := b.lookahead(nil, fixedNode(inst, nil, nil), nil, candidates); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{3, 1, 2}; !slices.Equal(candidates, want) {
+		t.Errorf("lookahead ranked %v, want %v", candidates, want)
+	}
+}
+
+func TestBranchOnElement(t *testing.T) {
+	// Subset 12 covers elements 0 and 3; element 1 is left with the free
+	// subsets 4, 5 and 6, and element 2 with 6, 7, 8 and 9.
+	inst := parseTestInstance(t, testInstance, LoadOptions{})
+	res := &subgradient{lambda: mat.NewVecDense(inst.NumElements, []float64{0, 5, 8, 0})}
+	node := fixedNode(inst, []int{12}, []int{3})
+
+	tests := []struct {
+		rule   BranchingRule
+		factor int
+		want   []int
+		kind   branchKind
+	}{
+		{BranchElementCandidates, 3, []int{6, 4, 5}, branchPartition},
+		{BranchElementCandidates, 8, []int{6, 4, 5}, branchPartition},
+		{BranchElementCandidates, 2, []int{6}, branchCompletePartition},
+		{BranchElementMultiplier, 4, []int{6, 7, 8, 9}, branchPartition},
+		{BranchElementMultiplier, 3, []int{6, 7}, branchCompletePartition},
+	}
+	for _, tt := range tests {
+		br := inst.newBrancher(tt.rule, tt.factor).branchOnElement(node, res)
+		got := make([]int, len(br.subsets))
+		for k, i := range br.subsets {
+			got[k] = int(i)
+		}
+		if !slices.Equal(got, tt.want) || br.kind != tt.kind {
+			t.Errorf("%v with factor %d: branched on %v (%v), want %v (%v)", tt.rule, tt.factor, got, br.kind, tt.want, tt.kind)
+		}
+		if children := len(br.fixings()); children > tt.factor {
+			t.Errorf("%v with factor %d: got %d children", tt.rule, tt.factor, children)
+		}
+		assertPartition(t, br)
+	}
+
+	br := inst.newBrancher(BranchElementCandidates, 2).branchOnElement(fixedNode(inst, []int{12, 6}, nil), res)
+	if !br.empty() {
+		t.Errorf("branched on %v with every element covered", br)
+	}
+}
+
+func TestBranchOnConflict(t *testing.T) {
+	inst := parseTestInstance(t, testInstance+"conflicts 3\n1 13 4.5\n2 3 1\n6 12 2\n", LoadOptions{})
+	uniform := func(v float64) *mat.VecDense {
+		vec := mat.NewVecDense(inst.NumSubsets, nil)
+		for i := range inst.NumSubsets {
+			vec.SetVec(i, v)
+		}
+		return vec
+	}
+
+	tests := []struct {
+		name     string
+		selected uint
+		average  float64
+		excluded []int
+		want     []int
+	}{
+		{"largest weighted penalty", 0, 0.5, nil, []int{0, 12}},
+		{"selected together", 1<<1 | 1<<2, 0, nil, []int{1, 2}},
+		{"fixed subset", 0, 0.5, []int{0}, []int{5, 11}},
+		{"no conflict", 1<<1 | 1<<5, 0, nil, nil},
+	}
+	for _, tt := range tests {
+		res := &subgradient{
+			sol:     &Solution{Subsets: selection(inst.NumSubsets, tt.selected)},
+			lambda:  mat.NewVecDense(inst.NumElements, nil),
+			average: uniform(tt.average),
+		}
+		node := fixedNode(inst, nil, tt.excluded)
+		br := inst.branchOnConflict(node, res)
+		got := make([]int, len(br.subsets))
+		for k, i := range br.subsets {
+			got[k] = int(i)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%v: branched on %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		if tt.want == nil {
+			// The rule falls back to fractional branching.
+			br, err := inst.newBrancher(BranchConflictPair, 2).branch(nil, node, res)
+			if err != nil || br.kind != branchCompletePartition || len(br.subsets) != 1 {
+				t.Errorf("%v: fell back to %v (%v)", tt.name, br, err)
+			}
+			continue
+		}
+		if br.kind != branchConflict || len(br.fixings()) != 3 {
+			t.Errorf("%v: got %v with %d children", tt.name, br, len(br.fixings()))
+		}
+		i, j := tt.want[0], tt.want[1]
+		for _, sel := range []uint{0, 1 << i, 1 << j, 1<<i | 1<<j} {
+			if matches := matchingChildren(br.fixings(), sel); len(matches) != 1 {
+				t.Errorf("%v: selection %b agrees with the children %v", tt.name, sel, matches)
+			}
+		}
+	}
+}
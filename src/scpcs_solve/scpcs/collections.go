@@ -1,5 +1,9 @@
 package scpcs
 
+import (
+	"container/heap"
+)
+
 type linkedListNode[T any] struct {
 	value T
 	next  *linkedListNode[T]
@@ -96,3 +100,96 @@ func (q *Queue[T]) Pop() T {
 func (q *Queue[T]) Size() int {
 	return q.list.size
 }
+
+type priorityItem[T any] struct {
+	value T
+	seq   int
+}
+
+type priorityHeap[T any] struct {
+	items []priorityItem[T]
+	less  func(x, y T) bool
+}
+
+func (h *priorityHeap[T]) Len() int {
+	return len(h.items)
+}
+
+func (h *priorityHeap[T]) Less(i, j int) bool {
+	if h.less(h.items[i].value, h.items[j].value) {
+		return true
+	}
+	if h.less(h.items[j].value, h.items[i].value) {
+		return false
+	}
+	return h.items[i].seq < h.items[j].seq
+}
+
+func (h *priorityHeap[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+}
+
+func (h *priorityHeap[T]) Push(x any) {
+	h.items = append(h.items, x.(priorityItem[T]))
+}
+
+func (h *priorityHeap[T]) Pop() any {
+	n := len(h.items)
+	item := h.items[n-1]
+	h.items[n-1] = priorityItem[T]{}
+	h.items = h.items[:n-1]
+	return item
+}
+
+// PriorityQueue is a Deque popping the smallest element according to less,
+// the first pushed among equal ones.
+type PriorityQueue[T any] struct {
+	heap *priorityHeap[T]
+	seq  int
+}
+
+func NewPriorityQueue[T any](less func(x, y T) bool) *PriorityQueue[T] {
+	return &PriorityQueue[T]{
+		heap: &priorityHeap[T]{less: less},
+	}
+}
+
+func (pq *PriorityQueue[T]) Push(e T) {
+	heap.Push(pq.heap, priorityItem[T]{value: e, seq: pq.seq})
+	pq.seq++
+}
+
+func (pq *PriorityQueue[T]) Pop() T {
+	if pq.heap.Len() == 0 {
+		var zero T
+		return zero
+	}
+	return heap.Pop(pq.heap).(priorityItem[T]).value
+}
+
+// Peek returns the element Pop would return without removing it.
+func (pq *PriorityQueue[T]) Peek() T {
+	if pq.heap.Len() == 0 {
+		var zero T
+		return zero
+	}
+	return pq.heap.items[0].value
+}
+
+func (pq *PriorityQueue[T]) Size() int {
+	return pq.heap.Len()
+}
+
+// Retain removes the elements for which keep returns false, keeping the order
+// of the remaining ones.
+func (pq *PriorityQueue[T]) Retain(keep func(T) bool) {
+	items := pq.heap.items[:0]
+	for _, item := range pq.heap.items {
+		if keep(item.value) {
+			items = append(items, item)
+		}
+	}
+	clear(pq.heap.items[len(items):])
+	pq.heap.items = items
+	heap.Init(pq.heap)
+}
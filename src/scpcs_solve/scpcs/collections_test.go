@@ -0,0 +1,72 @@
+package scpcs
+
+import (
+	"slices"
+	"testing"
+)
+
+type keyed struct {
+	key, id int
+}
+
+func drain[T any](d Deque[T]) []T {
+	var popped []T
+	for d.Size() > 0 {
+		popped = append(popped, d.Pop())
+	}
+	return popped
+}
+
+func TestDeques(t *testing.T) {
+	values := []int{3, 1, 4, 1, 5, 9, 2, 6}
+	tests := []struct {
+		name  string
+		deque Deque[int]
+		want  []int
+	}{
+		{"stack", NewStack[int](), []int{6, 2, 9, 5, 1, 4, 1, 3}},
+		{"queue", NewQueue[int](), []int{3, 1, 4, 1, 5, 9, 2, 6}},
+		{"priority queue", NewPriorityQueue(func(x, y int) bool { return x < y }), []int{1, 1, 2, 3, 4, 5, 6, 9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, v := range values {
+				tt.deque.Push(v)
+			}
+			if tt.deque.Size() != len(values) {
+				t.Errorf("got size %d, want %d", tt.deque.Size(), len(values))
+			}
+			if got := drain(tt.deque); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if got := tt.deque.Pop(); got != 0 {
+				t.Errorf("empty deque popped %v", got)
+			}
+		})
+	}
+}
+
+func TestPriorityQueue(t *testing.T) {
+	pq := NewPriorityQueue(func(x, y keyed) bool { return x.key < y.key })
+	for id, key := range []int{2, 1, 2, 0, 1, 2, 0} {
+		pq.Push(keyed{key, id})
+	}
+	if got := pq.Peek(); got != (keyed{0, 3}) {
+		t.Errorf("Peek: got %v, want {0 3}", got)
+	}
+
+	pq.Retain(func(e keyed) bool { return e.id != 3 && e.id != 4 })
+	if pq.Size() != 5 {
+		t.Errorf("got size %d after Retain, want 5", pq.Size())
+	}
+	pq.Push(keyed{1, 7})
+
+	// Equal keys pop in the order they were pushed.
+	want := []keyed{{0, 6}, {1, 1}, {1, 7}, {2, 0}, {2, 2}, {2, 5}}
+	if got := drain[keyed](pq); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := pq.Peek(); got != (keyed{}) {
+		t.Errorf("Peek on an empty queue: got %v", got)
+	}
+}
@@ -0,0 +1,149 @@
+package scpcs
+
+import (
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ConflictGraph is the sparse, symmetric adjacency of the conflict graph: for
+// each subset the sorted list of the subsets it conflicts with, together with
+// the penalties. Memory scales with the number of conflicts.
+type ConflictGraph struct {
+	neighbors [][]int
+	penalties [][]float64
+}
+
+func NewConflictGraph(numSubsets int) *ConflictGraph {
+	return &ConflictGraph{
+		neighbors: make([][]int, numSubsets),
+		penalties: make([][]float64, numSubsets),
+	}
+}
+
+// Add records the conflict between i and j. It returns false, leaving the
+// graph unchanged, when the conflict is already present.
+func (g *ConflictGraph) Add(i, j int, penalty float64) bool {
+	if !g.insert(i, j, penalty) {
+		return false
+	}
+	g.insert(j, i, penalty)
+	return true
+}
+
+func (g *ConflictGraph) insert(i, j int, penalty float64) bool {
+	k, found := slices.BinarySearch(g.neighbors[i], j)
+	if found {
+		return false
+	}
+	g.neighbors[i] = slices.Insert(g.neighbors[i], k, j)
+	g.penalties[i] = slices.Insert(g.penalties[i], k, penalty)
+	return true
+}
+
+// At returns the penalty of the conflict between i and j, 0 if they do not
+// conflict.
+func (g *ConflictGraph) At(i, j int) float64 {
+	k, found := slices.BinarySearch(g.neighbors[i], j)
+	if !found {
+		return 0
+	}
+	return g.penalties[i][k]
+}
+
+// Neighbors returns the subsets in conflict with i and the corresponding
+// penalties. The slices must not be modified.
+func (g *ConflictGraph) Neighbors(i int) ([]int, []float64) {
+	return g.neighbors[i], g.penalties[i]
+}
+
+func (g *ConflictGraph) Degree(i int) int {
+	return len(g.neighbors[i])
+}
+
+// Penalty returns the total penalty incurred by adding i to the selected
+// subsets.
+func (g *ConflictGraph) Penalty(i int, selected *mat.VecDense) (penalty float64) {
+	for k, j := range g.neighbors[i] {
+		if selected.AtVec(j) > 0.5 {
+			penalty += g.penalties[i][k]
+		}
+	}
+	return
+}
+
+type conflictEntry struct {
+	j       int
+	penalty float64
+}
+
+// computeConflicts derives the conflicts from the subsets intersections. The
+// intersections of subset i with the following subsets are counted by walking
+// the subsets covering each of its elements, so only the intersecting pairs
+// are visited unless the model may put disjoint subsets in conflict. The
+// subsets are split among the given number of goroutines.
+func (inst *Instance) computeConflicts(model PenaltyModel, threads int) error {
+	inst.initConflicts()
+	penalty := model.Penalties(inst)
+	onlyIntersecting := false
+	if m, ok := model.(IntersectingPenaltyModel); ok {
+		onlyIntersecting = m.OnlyIntersecting()
+	}
+	if threads <= 0 {
+		threads = runtime.NumCPU()
+	}
+
+	conflicts := make([][]conflictEntry, inst.NumSubsets)
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(threads, max(inst.NumSubsets, 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counts := make([]int, inst.NumSubsets)
+			touched := make([]int, 0)
+			for i := int(next.Add(1) - 1); i < inst.NumSubsets; i = int(next.Add(1) - 1) {
+				touched = touched[:0]
+				for _, e := range inst.Subsets.Col(i) {
+					row := inst.Subsets.Row(e)
+					start, _ := slices.BinarySearch(row, i+1)
+					for _, j := range row[start:] {
+						if counts[j] == 0 {
+							touched = append(touched, j)
+						}
+						counts[j]++
+					}
+				}
+
+				if onlyIntersecting {
+					slices.Sort(touched)
+					for _, j := range touched {
+						if p := penalty(i, j, float64(counts[j])); p > eps {
+							conflicts[i] = append(conflicts[i], conflictEntry{j, p})
+						}
+					}
+				} else {
+					for j := i + 1; j < inst.NumSubsets; j++ {
+						if p := penalty(i, j, float64(counts[j])); p > eps {
+							conflicts[i] = append(conflicts[i], conflictEntry{j, p})
+						}
+					}
+				}
+				for _, j := range touched {
+					counts[j] = 0
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, entries := range conflicts {
+		for _, c := range entries {
+			inst.setConflict(i, c.j, c.penalty)
+		}
+	}
+	return nil
+}
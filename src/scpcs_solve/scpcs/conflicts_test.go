@@ -0,0 +1,157 @@
+package scpcs
+
+import (
+	"slices"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestConflictGraph(t *testing.T) {
+	type conflict struct {
+		i, j    int
+		penalty float64
+		added   bool
+	}
+	conflicts := []conflict{
+		{0, 3, 2, true},
+		{2, 0, 1.5, true},
+		{3, 0, 7, false},
+		{1, 2, 4, true},
+		{0, 1, 3, true},
+		{2, 1, 1, false},
+	}
+	g := NewConflictGraph(5)
+	dense := mat.NewDense(5, 5, nil)
+	for _, c := range conflicts {
+		if got := g.Add(c.i, c.j, c.penalty); got != c.added {
+			t.Errorf("Add(%d, %d): got %v, want %v", c.i, c.j, got, c.added)
+		}
+		if c.added {
+			dense.Set(c.i, c.j, c.penalty)
+			dense.Set(c.j, c.i, c.penalty)
+		}
+	}
+
+	for i := range 5 {
+		var neighbors []int
+		var penalties []float64
+		for j := range 5 {
+			if got := g.At(i, j); got != dense.At(i, j) {
+				t.Errorf("At(%d, %d): got %v, want %v", i, j, got, dense.At(i, j))
+			}
+			if dense.At(i, j) != 0 {
+				neighbors = append(neighbors, j)
+				penalties = append(penalties, dense.At(i, j))
+			}
+		}
+		gotNeighbors, gotPenalties := g.Neighbors(i)
+		if !slices.Equal(gotNeighbors, neighbors) || !slices.Equal(gotPenalties, penalties) {
+			t.Errorf("Neighbors(%d): got %v %v, want %v %v", i, gotNeighbors, gotPenalties, neighbors, penalties)
+		}
+		if g.Degree(i) != len(neighbors) {
+			t.Errorf("Degree(%d): got %d, want %d", i, g.Degree(i), len(neighbors))
+		}
+	}
+
+	selected := mat.NewVecDense(5, []float64{1, 0, 1, 1, 0})
+	for i := range 5 {
+		want := 0.0
+		for j := range 5 {
+			want += dense.At(i, j) * selected.AtVec(j)
+		}
+		if got := g.Penalty(i, selected); got != want {
+			t.Errorf("Penalty(%d): got %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestComputeConflicts(t *testing.T) {
+	inst := parseTestInstance(t, testInstance, LoadOptions{})
+	a := inst.Subsets.Dense()
+	intersections := new(mat.Dense)
+	intersections.Mul(a.T(), a)
+
+	models := []PenaltyModel{
+		IntersectionPenalty{},
+		IntersectionPenalty{1, 2},
+		IntersectionPenalty{-1, 1},
+		JaccardPenalty{0.2, 0},
+		OverlapPenalty{0.5, 1},
+		ConstantPenalty{0, 3},
+		QuadraticPenalty{0, 1},
+		CostPenalty{},
+		CostPenalty{-1, 0.5},
+	}
+	for _, model := range models {
+		penalty := model.Penalties(inst)
+		for _, threads := range []int{1, 4} {
+			if err := inst.computeConflicts(model, threads); err != nil {
+				t.Fatalf("%#v: %v", model, err)
+			}
+			count := 0
+			for i := range inst.NumSubsets {
+				for j := range inst.NumSubsets {
+					want := 0.0
+					if i != j {
+						want = penalty(min(i, j), max(i, j), intersections.At(i, j))
+					}
+					if want <= eps {
+						want = 0
+					} else if i < j {
+						count++
+					}
+					if got := inst.Conflicts.At(i, j); got != want {
+						t.Errorf("%#v with %d threads: conflict %d-%d has penalty %v, want %v", model, threads, i, j, got, want)
+					}
+				}
+			}
+			if len(inst.ConflictsList) != count {
+				t.Errorf("%#v with %d threads: got %d conflicts, want %d", model, threads, len(inst.ConflictsList), count)
+			}
+		}
+	}
+}
+
+func TestPenaltyModels(t *testing.T) {
+	// Subset 0 costs 1 and covers element 0, subset 12 costs 2.5 and covers
+	// elements 0 and 3; the default weight is 12, the cost of subset 11 which
+	// covers a single element.
+	inst := parseTestInstance(t, testInstance, LoadOptions{})
+	tests := []struct {
+		name               string
+		threshold, weight  float64
+		intersection, want float64
+	}{
+		{"intersection", 0, 0, 1, 12},
+		{"intersection", 1, 2, 1, 0},
+		{"intersection", 1, 2, 3, 4},
+		{"jaccard", 0.4, 1, 1, 1},
+		{"jaccard", 0.5, 1, 1, 0},
+		{"overlap", 0.5, 2, 1, 2},
+		{"overlap", 1, 2, 1, 0},
+		{"constant", 0, 3, 1, 3},
+		{"constant", 1, 3, 1, 0},
+		{"quadratic", 1, 2, 3, 8},
+		{"quadratic", 1, 2, 1, 0},
+		{"cost", 0, 0, 1, 3.5},
+		{"cost", 0, 2, 1, 7},
+		{"cost", 1, 0, 1, 0},
+	}
+	for _, tt := range tests {
+		model, err := ParsePenaltyModel(tt.name, tt.threshold, tt.weight)
+		if err != nil {
+			t.Fatalf("ParsePenaltyModel(%q): %v", tt.name, err)
+		}
+		got := model.Penalties(inst)(0, 12, tt.intersection)
+		if got < 0 {
+			got = 0
+		}
+		if got != tt.want {
+			t.Errorf("%#v: got penalty %v for an intersection of %v, want %v", model, got, tt.intersection, tt.want)
+		}
+	}
+	if _, err := ParsePenaltyModel("unknown", 0, 0); err == nil {
+		t.Errorf("ParsePenaltyModel accepted an unknown model")
+	}
+}
@@ -0,0 +1,199 @@
+package scpcs
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// Evaluator incrementally evaluates a selection of subsets. It keeps the
+// number of selected subsets covering each element and the total cost,
+// conflict penalties included, so that adding, removing or swapping a subset
+// costs time proportional to its size and conflict degree. An Evaluator is not
+// safe for concurrent use.
+type Evaluator struct {
+	inst      *Instance
+	selected  []bool
+	coverage  []int
+	uncovered int
+	cost      float64
+}
+
+func (inst *Instance) NewEvaluator() *Evaluator {
+	return &Evaluator{
+		inst:      inst,
+		selected:  make([]bool, inst.NumSubsets),
+		coverage:  make([]int, inst.NumElements),
+		uncovered: inst.NumElements,
+	}
+}
+
+// Load replaces the current selection with the subsets set in selected.
+func (ev *Evaluator) Load(selected *mat.VecDense) {
+	ev.Clear()
+	for i := range ev.inst.NumSubsets {
+		if selected.AtVec(i) > 0.5 {
+			ev.Add(i)
+		}
+	}
+}
+
+func (ev *Evaluator) Clear() {
+	clear(ev.selected)
+	clear(ev.coverage)
+	ev.uncovered = ev.inst.NumElements
+	ev.cost = 0
+}
+
+func (ev *Evaluator) Selected(i int) bool {
+	return ev.selected[i]
+}
+
+// Coverage returns the number of selected subsets covering the element.
+func (ev *Evaluator) Coverage(element int) int {
+	return ev.coverage[element]
+}
+
+func (ev *Evaluator) Cost() float64 {
+	return ev.cost
+}
+
+func (ev *Evaluator) Uncovered() int {
+	return ev.uncovered
+}
+
+func (ev *Evaluator) Feasible() bool {
+	return ev.uncovered == 0
+}
+
+// penalty returns the penalties between i and the selected subsets.
+func (ev *Evaluator) penalty(i int) (penalty float64) {
+	neighbors, penalties := ev.inst.Conflicts.Neighbors(i)
+	for k, j := range neighbors {
+		if ev.selected[j] {
+			penalty += penalties[k]
+		}
+	}
+	return
+}
+
+// AddDelta returns the change of the cost caused by adding subset i.
+func (ev *Evaluator) AddDelta(i int) float64 {
+	if ev.selected[i] {
+		return 0
+	}
+	return ev.inst.Costs.AtVec(i) + ev.penalty(i)
+}
+
+// RemoveDelta returns the change of the cost caused by removing subset i.
+func (ev *Evaluator) RemoveDelta(i int) float64 {
+	if !ev.selected[i] {
+		return 0
+	}
+	return -ev.inst.Costs.AtVec(i) - ev.penalty(i)
+}
+
+// SwapDelta returns the change of the cost caused by replacing the selected
+// subset out with in.
+func (ev *Evaluator) SwapDelta(out, in int) float64 {
+	if !ev.selected[out] || ev.selected[in] {
+		return ev.RemoveDelta(out) + ev.AddDelta(in)
+	}
+	return ev.RemoveDelta(out) + ev.AddDelta(in) - ev.inst.Conflicts.At(out, in)
+}
+
+// NewlyCovered returns the number of uncovered elements that adding subset i
+// would cover.
+func (ev *Evaluator) NewlyCovered(i int) (count int) {
+	if ev.selected[i] {
+		return 0
+	}
+	for _, e := range ev.inst.Subsets.Col(i) {
+		if ev.coverage[e] == 0 {
+			count++
+		}
+	}
+	return
+}
+
+// Uncovers returns the number of elements that removing subset i would leave
+// uncovered. A selected subset with no such elements is redundant.
+func (ev *Evaluator) Uncovers(i int) (count int) {
+	if !ev.selected[i] {
+		return 0
+	}
+	for _, e := range ev.inst.Subsets.Col(i) {
+		if ev.coverage[e] == 1 {
+			count++
+		}
+	}
+	return
+}
+
+// SwapFeasible reports whether replacing the selected subset out with in
+// keeps every element covered that is covered now.
+func (ev *Evaluator) SwapFeasible(out, in int) bool {
+	if !ev.selected[out] {
+		return true
+	}
+	inCol := ev.inst.Subsets.Col(in)
+	k := 0
+	for _, e := range ev.inst.Subsets.Col(out) {
+		if ev.coverage[e] != 1 {
+			continue
+		}
+		for k < len(inCol) && inCol[k] < e {
+			k++
+		}
+		if k == len(inCol) || inCol[k] != e {
+			return false
+		}
+	}
+	return true
+}
+
+func (ev *Evaluator) Add(i int) {
+	if ev.selected[i] {
+		return
+	}
+	ev.cost += ev.AddDelta(i)
+	ev.selected[i] = true
+	for _, e := range ev.inst.Subsets.Col(i) {
+		if ev.coverage[e] == 0 {
+			ev.uncovered--
+		}
+		ev.coverage[e]++
+	}
+}
+
+func (ev *Evaluator) Remove(i int) {
+	if !ev.selected[i] {
+		return
+	}
+	ev.cost += ev.RemoveDelta(i)
+	ev.selected[i] = false
+	for _, e := range ev.inst.Subsets.Col(i) {
+		ev.coverage[e]--
+		if ev.coverage[e] == 0 {
+			ev.uncovered++
+		}
+	}
+}
+
+func (ev *Evaluator) Swap(out, in int) {
+	ev.Remove(out)
+	ev.Add(in)
+}
+
+// Solution returns the current selection and its cost, recomputed from
+// scratch to drop the rounding errors accumulated by the updates.
+func (ev *Evaluator) Solution() *Solution {
+	subsets := mat.NewVecDense(ev.inst.NumSubsets, nil)
+	for i, s := range ev.selected {
+		if s {
+			subsets.SetVec(i, 1)
+		}
+	}
+	return &Solution{
+		Subsets:   subsets,
+		TotalCost: ev.inst.getCost(subsets),
+	}
+}
@@ -0,0 +1,123 @@
+package scpcs
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func selection(n int, mask uint) *mat.VecDense {
+	v := mat.NewVecDense(n, nil)
+	for i := range n {
+		if mask&(1<<i) != 0 {
+			v.SetVec(i, 1)
+		}
+	}
+	return v
+}
+
+func coveredBy(inst *Instance, mask uint) []bool {
+	coverage := mat.NewVecDense(inst.NumElements, nil)
+	inst.Subsets.MulVecTo(coverage, selection(inst.NumSubsets, mask))
+	covered := make([]bool, inst.NumElements)
+	for e := range inst.NumElements {
+		covered[e] = coverage.AtVec(e) > 0
+	}
+	return covered
+}
+
+func uncoveredBy(inst *Instance, mask uint) (count int) {
+	for _, c := range coveredBy(inst, mask) {
+		if !c {
+			count++
+		}
+	}
+	return
+}
+
+// keepsCovered reports whether the elements covered by from are covered by to.
+func keepsCovered(inst *Instance, from, to uint) bool {
+	before, after := coveredBy(inst, from), coveredBy(inst, to)
+	for e := range inst.NumElements {
+		if before[e] && !after[e] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEvaluatorDeltas(t *testing.T) {
+	instances := map[string]*Instance{
+		"derived": parseTestInstance(t, testInstance, LoadOptions{}),
+		"given":   parseTestInstance(t, testInstance+"conflicts 3\n1 13 4.5\n2 3 1\n6 12 2\n", LoadOptions{}),
+	}
+	for name, inst := range instances {
+		t.Run(name, func(t *testing.T) {
+			n := inst.NumSubsets
+			cost := func(mask uint) float64 {
+				return inst.getCost(selection(n, mask))
+			}
+			ev := inst.NewEvaluator()
+			for mask := uint(0); mask < 1<<n; mask += 29 {
+				ev.Load(selection(n, mask))
+				if !almostEqual(ev.Cost(), cost(mask)) {
+					t.Fatalf("selection %b: got cost %v, want %v", mask, ev.Cost(), cost(mask))
+				}
+				uncovered := uncoveredBy(inst, mask)
+				if ev.Uncovered() != uncovered || ev.Feasible() != (uncovered == 0) {
+					t.Fatalf("selection %b: got %d uncovered, want %d", mask, ev.Uncovered(), uncovered)
+				}
+
+				for i := range n {
+					bit := uint(1) << i
+					if got, want := ev.AddDelta(i), cost(mask|bit)-cost(mask); !almostEqual(got, want) {
+						t.Errorf("selection %b: AddDelta(%d) = %v, want %v", mask, i, got, want)
+					}
+					if got, want := ev.RemoveDelta(i), cost(mask&^bit)-cost(mask); !almostEqual(got, want) {
+						t.Errorf("selection %b: RemoveDelta(%d) = %v, want %v", mask, i, got, want)
+					}
+					if got, want := ev.NewlyCovered(i), uncovered-uncoveredBy(inst, mask|bit); got != want {
+						t.Errorf("selection %b: NewlyCovered(%d) = %d, want %d", mask, i, got, want)
+					}
+					if got, want := ev.Uncovers(i), uncoveredBy(inst, mask&^bit)-uncovered; got != want {
+						t.Errorf("selection %b: Uncovers(%d) = %d, want %d", mask, i, got, want)
+					}
+					if mask&bit == 0 {
+						continue
+					}
+					for j := range n {
+						if j == i {
+							continue
+						}
+						swapped := mask&^bit | 1<<j
+						if got, want := ev.SwapDelta(i, j), cost(swapped)-cost(mask); !almostEqual(got, want) {
+							t.Errorf("selection %b: SwapDelta(%d, %d) = %v, want %v", mask, i, j, got, want)
+						}
+						if got, want := ev.SwapFeasible(i, j), keepsCovered(inst, mask, swapped); got != want {
+							t.Errorf("selection %b: SwapFeasible(%d, %d) = %v, want %v", mask, i, j, got, want)
+						}
+					}
+				}
+
+				cur := mask
+				for i := range n {
+					before := ev.Cost()
+					if cur&(1<<i) != 0 {
+						ev.Remove(i)
+					} else {
+						ev.Add(i)
+					}
+					flipped := cur ^ 1<<i
+					if !almostEqual(ev.Cost(), cost(flipped)) || ev.Uncovered() != uncoveredBy(inst, flipped) {
+						t.Fatalf("selection %b: flipping %d from cost %v gives %v and %d uncovered, want %v and %d",
+							cur, i, before, ev.Cost(), ev.Uncovered(), cost(flipped), uncoveredBy(inst, flipped))
+					}
+					cur = flipped
+				}
+				if sol := ev.Solution(); !almostEqual(sol.TotalCost, cost(cur)) {
+					t.Errorf("selection %b: got solution cost %v, want %v", cur, sol.TotalCost, cost(cur))
+				}
+			}
+		})
+	}
+}
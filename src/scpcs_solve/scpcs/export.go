@@ -0,0 +1,319 @@
+package scpcs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/lanl/highs"
+)
+
+type ModelFormat int
+
+const (
+	ModelMPS ModelFormat = iota
+	ModelLP
+)
+
+func ParseModelFormat(s string) (ModelFormat, error) {
+	switch strings.ToLower(s) {
+	case "mps":
+		return ModelMPS, nil
+	case "lp":
+		return ModelLP, nil
+	}
+	return ModelMPS, fmt.Errorf("unknown model format \"%v\"", s)
+}
+
+// modelNames returns the names of the columns and rows of the model built by
+// defSCPCS: x_j for the subsets, y_i_j for the conflicts, cover_e for the
+// covering constraints and link_i_j for the x_i + x_j - y_ij <= 1 constraints.
+// Indices are 0-based.
+func (inst *Instance) modelNames() (cols, rows []string) {
+	cols = make([]string, 0, inst.NumSubsets+len(inst.ConflictsList))
+	rows = make([]string, 0, inst.NumElements+len(inst.ConflictsList))
+	for j := range inst.NumSubsets {
+		cols = append(cols, fmt.Sprintf("x_%d", j))
+	}
+	for _, pair := range inst.ConflictsList {
+		cols = append(cols, fmt.Sprintf("y_%d_%d", pair[0], pair[1]))
+	}
+	for i := range inst.NumElements {
+		rows = append(rows, fmt.Sprintf("cover_%d", i))
+	}
+	for _, pair := range inst.ConflictsList {
+		rows = append(rows, fmt.Sprintf("link_%d_%d", pair[0], pair[1]))
+	}
+	return
+}
+
+// ExportModel writes the MILP model solved by Solve in free MPS or CPLEX LP
+// format, so that it can be inspected or handed to other solvers.
+func (inst *Instance) ExportModel(w io.Writer, format ModelFormat) error {
+	lp := inst.defSCPCS()
+	cols, rows := inst.modelNames()
+	bw := bufio.NewWriter(w)
+	switch format {
+	case ModelMPS:
+		writeMPS(bw, lp, cols, rows)
+	case ModelLP:
+		writeLP(bw, lp, cols, rows)
+	default:
+		return fmt.Errorf("unsupported model format %d", format)
+	}
+	return bw.Flush()
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func colBounds(lp *highs.Model, j int) (float64, float64) {
+	lb, ub := 0.0, math.Inf(1)
+	if j < len(lp.ColLower) {
+		lb = lp.ColLower[j]
+	}
+	if j < len(lp.ColUpper) {
+		ub = lp.ColUpper[j]
+	}
+	return lb, ub
+}
+
+func isIntegerCol(lp *highs.Model, j int) bool {
+	return j < len(lp.VarTypes) && lp.VarTypes[j] == highs.IntegerType
+}
+
+func writeMPS(w *bufio.Writer, lp *highs.Model, cols, rows []string) {
+	colEntries := make([][]highs.Nonzero, len(cols))
+	for _, nz := range lp.ConstMatrix {
+		colEntries[nz.Col] = append(colEntries[nz.Col], nz)
+	}
+
+	fmt.Fprintln(w, "NAME scpcs")
+	if lp.Maximize {
+		fmt.Fprintln(w, "OBJSENSE")
+		fmt.Fprintln(w, "    MAX")
+	}
+
+	fmt.Fprintln(w, "ROWS")
+	fmt.Fprintln(w, " N obj")
+	for i, name := range rows {
+		lb, ub := lp.RowLower[i], lp.RowUpper[i]
+		switch {
+		case lb == ub:
+			fmt.Fprintln(w, " E", name)
+		case math.IsInf(lb, -1):
+			fmt.Fprintln(w, " L", name)
+		default:
+			fmt.Fprintln(w, " G", name)
+		}
+	}
+
+	fmt.Fprintln(w, "COLUMNS")
+	integer := false
+	for j, name := range cols {
+		if isIntegerCol(lp, j) != integer {
+			integer = !integer
+			marker := "INTEND"
+			if integer {
+				marker = "INTORG"
+			}
+			fmt.Fprintf(w, "    MARKER 'MARKER' '%v'\n", marker)
+		}
+		cost := 0.0
+		if j < len(lp.ColCosts) {
+			cost = lp.ColCosts[j]
+		}
+		// A column is declared only by its COLUMNS lines, so one without
+		// entries gets an explicit zero cost.
+		if cost != 0 || len(colEntries[j]) == 0 {
+			fmt.Fprintf(w, "    %v obj %v\n", name, formatNumber(cost))
+		}
+		for _, nz := range colEntries[j] {
+			fmt.Fprintf(w, "    %v %v %v\n", name, rows[nz.Row], formatNumber(nz.Val))
+		}
+	}
+	if integer {
+		fmt.Fprintln(w, "    MARKER 'MARKER' 'INTEND'")
+	}
+
+	fmt.Fprintln(w, "RHS")
+	if lp.Offset != 0 {
+		fmt.Fprintf(w, "    rhs obj %v\n", formatNumber(-lp.Offset))
+	}
+	for i, name := range rows {
+		lb, ub := lp.RowLower[i], lp.RowUpper[i]
+		rhs := lb
+		if math.IsInf(lb, -1) {
+			rhs = ub
+		}
+		if rhs != 0 && !math.IsInf(rhs, 0) {
+			fmt.Fprintf(w, "    rhs %v %v\n", name, formatNumber(rhs))
+		}
+	}
+
+	ranged := false
+	for i, name := range rows {
+		lb, ub := lp.RowLower[i], lp.RowUpper[i]
+		if lb != ub && !math.IsInf(lb, -1) && !math.IsInf(ub, 1) {
+			if !ranged {
+				fmt.Fprintln(w, "RANGES")
+				ranged = true
+			}
+			fmt.Fprintf(w, "    rng %v %v\n", name, formatNumber(ub-lb))
+		}
+	}
+
+	fmt.Fprintln(w, "BOUNDS")
+	for j, name := range cols {
+		lb, ub := colBounds(lp, j)
+		switch {
+		case isIntegerCol(lp, j) && lb == 0 && ub == 1:
+			fmt.Fprintf(w, " BV bnd %v\n", name)
+		case lb == ub:
+			fmt.Fprintf(w, " FX bnd %v %v\n", name, formatNumber(lb))
+		default:
+			if math.IsInf(lb, -1) {
+				fmt.Fprintf(w, " MI bnd %v\n", name)
+			} else if lb != 0 {
+				fmt.Fprintf(w, " LO bnd %v %v\n", name, formatNumber(lb))
+			}
+			if !math.IsInf(ub, 1) {
+				fmt.Fprintf(w, " UP bnd %v %v\n", name, formatNumber(ub))
+			}
+		}
+	}
+	fmt.Fprintln(w, "ENDATA")
+}
+
+// writeLinearExpr writes a sum of terms, breaking the line every few terms as
+// CPLEX LP readers limit the line length.
+func writeLinearExpr(w *bufio.Writer, coeffs []float64, names []string, cols []int) {
+	written := 0
+	for k, j := range cols {
+		c := coeffs[k]
+		if c == 0 {
+			continue
+		}
+		if written > 0 && written%10 == 0 {
+			w.WriteString("\n   ")
+		}
+		sign := "+"
+		if c < 0 {
+			sign = "-"
+			c = -c
+		}
+		if written > 0 || sign == "-" {
+			fmt.Fprintf(w, " %v", sign)
+		}
+		if c == 1 {
+			fmt.Fprintf(w, " %v", names[j])
+		} else {
+			fmt.Fprintf(w, " %v %v", formatNumber(c), names[j])
+		}
+		written++
+	}
+	if written > 0 {
+		return
+	}
+	// Some readers reject a constant expression, so a column is named when
+	// the model has any.
+	if len(names) == 0 {
+		w.WriteString(" 0")
+		return
+	}
+	fmt.Fprintf(w, " 0 %v", names[0])
+}
+
+func writeLP(w *bufio.Writer, lp *highs.Model, cols, rows []string) {
+	rowCols := make([][]int, len(rows))
+	rowCoeffs := make([][]float64, len(rows))
+	for _, nz := range lp.ConstMatrix {
+		rowCols[nz.Row] = append(rowCols[nz.Row], nz.Col)
+		rowCoeffs[nz.Row] = append(rowCoeffs[nz.Row], nz.Val)
+	}
+
+	fmt.Fprintln(w, "\\ Problem: scpcs")
+	if lp.Maximize {
+		fmt.Fprintln(w, "Maximize")
+	} else {
+		fmt.Fprintln(w, "Minimize")
+	}
+	allCols := make([]int, len(cols))
+	costs := make([]float64, len(cols))
+	for j := range cols {
+		allCols[j] = j
+		if j < len(lp.ColCosts) {
+			costs[j] = lp.ColCosts[j]
+		}
+	}
+	w.WriteString(" obj:")
+	writeLinearExpr(w, costs, cols, allCols)
+	if lp.Offset != 0 {
+		fmt.Fprintf(w, " + %v", formatNumber(lp.Offset))
+	}
+	w.WriteRune('\n')
+
+	fmt.Fprintln(w, "Subject To")
+	for i, name := range rows {
+		lb, ub := lp.RowLower[i], lp.RowUpper[i]
+		fmt.Fprintf(w, " %v:", name)
+		if lb != ub && !math.IsInf(lb, -1) && !math.IsInf(ub, 1) {
+			fmt.Fprintf(w, " %v <=", formatNumber(lb))
+			writeLinearExpr(w, rowCoeffs[i], cols, rowCols[i])
+			fmt.Fprintf(w, " <= %v\n", formatNumber(ub))
+			continue
+		}
+		writeLinearExpr(w, rowCoeffs[i], cols, rowCols[i])
+		switch {
+		case lb == ub:
+			fmt.Fprintf(w, " = %v\n", formatNumber(lb))
+		case math.IsInf(lb, -1):
+			fmt.Fprintf(w, " <= %v\n", formatNumber(ub))
+		default:
+			fmt.Fprintf(w, " >= %v\n", formatNumber(lb))
+		}
+	}
+
+	fmt.Fprintln(w, "Bounds")
+	binaries := make([]string, 0)
+	generals := make([]string, 0)
+	for j, name := range cols {
+		lb, ub := colBounds(lp, j)
+		if isIntegerCol(lp, j) {
+			if lb == 0 && ub == 1 {
+				binaries = append(binaries, name)
+				continue
+			}
+			generals = append(generals, name)
+		}
+		switch {
+		case lb == ub:
+			fmt.Fprintf(w, " %v = %v\n", name, formatNumber(lb))
+		case math.IsInf(lb, -1) && math.IsInf(ub, 1):
+			fmt.Fprintf(w, " %v free\n", name)
+		case math.IsInf(ub, 1):
+			fmt.Fprintf(w, " %v >= %v\n", name, formatNumber(lb))
+		case math.IsInf(lb, -1):
+			fmt.Fprintf(w, " -inf <= %v <= %v\n", name, formatNumber(ub))
+		default:
+			fmt.Fprintf(w, " %v <= %v <= %v\n", formatNumber(lb), name, formatNumber(ub))
+		}
+	}
+	if len(binaries) > 0 {
+		fmt.Fprintln(w, "Binaries")
+		for _, name := range binaries {
+			fmt.Fprintln(w, "", name)
+		}
+	}
+	if len(generals) > 0 {
+		fmt.Fprintln(w, "Generals")
+		for _, name := range generals {
+			fmt.Fprintln(w, "", name)
+		}
+	}
+	fmt.Fprintln(w, "End")
+}
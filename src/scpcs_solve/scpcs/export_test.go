@@ -0,0 +1,82 @@
+package scpcs
+
+import (
+	"bytes"
+	"testing"
+)
+
+// exportInstance has a subset of cost 0 covering no element, whose column
+// has no entry in the model.
+const exportInstance = `2 3
+1 2 0
+2 1 2
+1 2
+conflicts 1
+1 2 3
+`
+
+func TestExportModel(t *testing.T) {
+	tests := []struct {
+		format ModelFormat
+		want   string
+	}{
+		{ModelMPS, `NAME scpcs
+ROWS
+ N obj
+ G cover_0
+ G cover_1
+ G link_0_1
+COLUMNS
+    MARKER 'MARKER' 'INTORG'
+    x_0 obj 1
+    x_0 cover_0 1
+    x_0 link_0_1 1
+    x_1 obj 2
+    x_1 cover_0 1
+    x_1 cover_1 1
+    x_1 link_0_1 1
+    x_2 obj 0
+    y_0_1 obj 3
+    y_0_1 link_0_1 -1
+    MARKER 'MARKER' 'INTEND'
+RHS
+    rhs cover_0 1
+    rhs cover_1 1
+RANGES
+    rng cover_0 1
+    rng cover_1 1
+    rng link_0_1 1
+BOUNDS
+ BV bnd x_0
+ BV bnd x_1
+ BV bnd x_2
+ BV bnd y_0_1
+ENDATA
+`},
+		{ModelLP, `\ Problem: scpcs
+Minimize
+ obj: x_0 + 2 x_1 + 3 y_0_1
+Subject To
+ cover_0: 1 <= x_0 + x_1 <= 2
+ cover_1: 1 <= x_1 <= 2
+ link_0_1: 0 <= x_0 + x_1 - y_0_1 <= 1
+Bounds
+Binaries
+ x_0
+ x_1
+ x_2
+ y_0_1
+End
+`},
+	}
+	inst := parseTestInstance(t, exportInstance, LoadOptions{})
+	for _, tt := range tests {
+		buf := new(bytes.Buffer)
+		if err := inst.ExportModel(buf, tt.format); err != nil {
+			t.Fatalf("format %d: %v", tt.format, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("format %d: got\n%v\nwant\n%v", tt.format, buf.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package scpcs
+
+import (
+	"errors"
+	"flag"
+	"strconv"
+)
+
+// InstanceFlags defines the -format, -threshold, -penalty and -penalty-weight
+// flags shared by the commands loading instances, and returns the options they
+// set when fs is parsed.
+func InstanceFlags(fs *flag.FlagSet) *LoadOptions {
+	opts := &LoadOptions{Penalty: IntersectionPenalty{}}
+	penaltyName := "intersection"
+	var threshold, weight float64
+	updatePenalty := func() (err error) {
+		opts.Penalty, err = ParsePenaltyModel(penaltyName, threshold, weight)
+		return
+	}
+	parseFloat := func(dst *float64) func(string) error {
+		return func(s string) (err error) {
+			*dst, err = strconv.ParseFloat(s, 64)
+			if err != nil {
+				return errors.New("parse error")
+			}
+			return updatePenalty()
+		}
+	}
+
+	fs.Func("format", "the instance file format: auto, native, orlib, rail or json (default auto)", func(s string) (err error) {
+		opts.Format, err = ParseFormat(s)
+		return
+	})
+	fs.Func("threshold", "the minimum intersection size, or similarity for jaccard and overlap, between subsets to be considered in conflict (default 0)", parseFloat(&threshold))
+	fs.Func("penalty", "the conflict penalty model: intersection, jaccard, overlap, constant, quadratic or cost (default intersection)", func(s string) error {
+		penaltyName = s
+		return updatePenalty()
+	})
+	fs.Func("penalty-weight", "the weight of the conflict penalties, 0 for the default of the penalty model (default 0)", parseFloat(&weight))
+	return opts
+}
@@ -0,0 +1,95 @@
+package scpcs
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	sniffSize  = 1 << 20
+	sniffLines = 5
+)
+
+type Format int
+
+const (
+	FormatAuto Format = iota
+	FormatNative
+	FormatORLib
+	FormatRail
+	FormatJSON
+)
+
+var formatNames = []string{
+	FormatAuto:   "auto",
+	FormatNative: "native",
+	FormatORLib:  "orlib",
+	FormatRail:   "rail",
+	FormatJSON:   "json",
+}
+
+func (f Format) String() string {
+	if f < 0 || int(f) >= len(formatNames) {
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+	return formatNames[f]
+}
+
+func ParseFormat(s string) (Format, error) {
+	for f, name := range formatNames {
+		if strings.EqualFold(s, name) {
+			return Format(f), nil
+		}
+	}
+	return FormatAuto, fmt.Errorf("unknown instance format \"%v\"", s)
+}
+
+// sniffFormat guesses the format of an instance by looking at the lines
+// following the header without consuming the reader. The native format keeps
+// all the costs on the second line, the rail format starts every column line
+// with its cost and its size, while the OR-Library format wraps costs and rows
+// over many lines. JSON instances are recognized by their opening brace.
+func sniffFormat(r *bufio.Reader) Format {
+	data, _ := r.Peek(sniffSize)
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		return FormatJSON
+	}
+	lines := strings.SplitN(string(data), "\n", sniffLines+2)
+	if len(lines) < 3 {
+		return FormatNative
+	}
+
+	header := strings.Fields(lines[0])
+	if len(header) < 2 {
+		return FormatNative
+	}
+	numSubsets, err := strconv.Atoi(header[1])
+	if err != nil {
+		return FormatNative
+	}
+
+	body := lines[1 : len(lines)-1]
+	if len(strings.Fields(body[0])) == numSubsets && (len(body) < 2 || isNativeRow(body[1])) {
+		return FormatNative
+	}
+	for _, line := range body {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return FormatORLib
+		}
+		size, err := strconv.Atoi(fields[1])
+		if err != nil || size != len(fields)-2 {
+			return FormatORLib
+		}
+	}
+	return FormatRail
+}
+
+// isNativeRow tells a native row, listing its subsets on the same line as
+// their number, from an OR-Library row whose subsets start on the next line.
+func isNativeRow(line string) bool {
+	fields := strings.Fields(line)
+	return len(fields) != 1 || fields[0] == "0"
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
-	"runtime"
 	"time"
 
 	"github.com/tomcraven/goga"
@@ -32,6 +31,16 @@ func getSelectedFromGenome(g goga.Genome) (selected *mat.VecDense) {
 	return
 }
 
+func evaluateGenome(inst *Instance, g goga.Genome) *Evaluator {
+	ev := inst.NewEvaluator()
+	for i, v := range g.GetBits().GetAll() {
+		if v == 1 {
+			ev.Add(i)
+		}
+	}
+	return ev
+}
+
 func (bestGenome *selectionSimulator) OnBeginSimulation() {
 }
 func (sms *selectionSimulator) OnEndSimulation() {
@@ -39,14 +48,9 @@ func (sms *selectionSimulator) OnEndSimulation() {
 }
 
 func (sms *selectionSimulator) Simulate(g goga.Genome) {
-	bits := g.GetBits().GetAll()
-	selected := mat.NewVecDense(sms.Instance.NumSubsets, nil)
-	for i, v := range bits {
-		selected.SetVec(i, float64(v))
-	}
-
-	if sms.Instance.isFeasible(selected) {
-		g.SetFitness(sms.TotalCost + 2 - int(sms.Instance.getCost(selected)))
+	ev := evaluateGenome(sms.Instance, g)
+	if ev.Feasible() {
+		g.SetFitness(sms.TotalCost + 2 - int(ev.Cost()))
 	} else {
 		g.SetFitness(1)
 	}
@@ -63,11 +67,12 @@ type myBitsetCreate struct {
 func (bc *myBitsetCreate) Go() goga.Bitset {
 	b := goga.Bitset{}
 	b.Create(bc.Instance.NumSubsets)
-	for i := range bc.SolutionNode.FixedSubsets {
-		b.Set(i, int(math.Round(bc.SolutionNode.PrimalSolution.Subsets.At(i, 0))))
-	}
-	for i := bc.SolutionNode.FixedSubsets; i < bc.Instance.NumSubsets; i++ {
-		b.Set(i, rand.Intn(2))
+	for i := range bc.Instance.NumSubsets {
+		if bc.SolutionNode.isFixed(i) {
+			b.Set(i, int(math.Round(bc.SolutionNode.PrimalSolution.Subsets.At(i, 0))))
+		} else {
+			b.Set(i, rand.Intn(2))
+		}
 	}
 	return b
 }
@@ -78,17 +83,25 @@ type myEliteConsumer struct {
 }
 
 func (ec *myEliteConsumer) OnElite(g goga.Genome) {
-	if (ec.BestGenome == nil || ec.BestGenome.GetFitness() < g.GetFitness()) && ec.Instance.isFeasible(getSelectedFromGenome(g)) {
+	if (ec.BestGenome == nil || ec.BestGenome.GetFitness() < g.GetFitness()) && evaluateGenome(ec.Instance, g).Feasible() {
 		ec.BestGenome = g
 	}
 }
 
-func (inst *Instance) geneticHeuristic(partialSol *Node, rounds int) *Solution {
+func (inst *Instance) geneticHeuristic(partialSol *Node, rounds, threads int) *Solution {
+	free := make([]int, 0, inst.NumSubsets-partialSol.FixedSubsets)
+	for i := range inst.NumSubsets {
+		if !partialSol.isFixed(i) {
+			free = append(free, i)
+		}
+	}
 	partialMutate := func(g1, g2 goga.Genome) (goga.Genome, goga.Genome) {
 		g1BitsOrig := g1.GetBits()
 		g1Bits := g1BitsOrig.CreateCopy()
-		randomBit := partialSol.FixedSubsets + rand.Intn(inst.NumElements-partialSol.FixedSubsets)
-		g1Bits.Set(randomBit, 1-g1Bits.Get(randomBit))
+		if len(free) > 0 {
+			randomBit := free[rand.Intn(len(free))]
+			g1Bits.Set(randomBit, 1-g1Bits.Get(randomBit))
+		}
 		return goga.NewGenome(g1Bits), goga.NewGenome(*g2.GetBits())
 	}
 
@@ -125,7 +138,7 @@ func (inst *Instance) geneticHeuristic(partialSol *Node, rounds int) *Solution {
 			{P: 1, F: goga.Roulette},
 		},
 	)
-	genAlgo.Init(populationSize, runtime.NumCPU())
+	genAlgo.Init(populationSize, threads)
 
 	noImprovRounds := 0
 	lastFitness := math.MinInt
@@ -1,44 +1,110 @@
 package scpcs
 
 import (
+	"cmp"
 	"fmt"
+	"math"
+	"slices"
 
-	"gonum.org/v1/gonum/mat"
 	"gopkg.in/dnaeon/go-priorityqueue.v1"
 )
 
-func (inst *Instance) greedyRepair(node *Node) (*Solution, error) {
+// GreedyScore is the function ranking the subsets in the greedy repair, given
+// the cost of adding a subset, conflict penalties included, and the number of
+// elements it newly covers.
+type GreedyScore int
+
+const (
+	ScoreRatio GreedyScore = iota
+	ScoreCostLog
+	ScoreCostSqrt
+)
+
+var greedyScoreNames = []string{
+	ScoreRatio:    "ratio",
+	ScoreCostLog:  "log",
+	ScoreCostSqrt: "sqrt",
+}
+
+func (s GreedyScore) String() string {
+	if s < 0 || int(s) >= len(greedyScoreNames) {
+		return fmt.Sprintf("GreedyScore(%d)", int(s))
+	}
+	return greedyScoreNames[s]
+}
+
+func ParseGreedyScore(s string) (GreedyScore, error) {
+	i := slices.Index(greedyScoreNames, s)
+	if i < 0 {
+		return 0, fmt.Errorf("unknown greedy score \"%v\", expected one of %v", s, greedyScoreNames)
+	}
+	return GreedyScore(i), nil
+}
+
+func (s GreedyScore) score(cost float64, covered int) float64 {
+	switch s {
+	case ScoreCostLog:
+		return cost / math.Log2(float64(covered)+1)
+	case ScoreCostSqrt:
+		return cost / math.Sqrt(float64(covered))
+	}
+	return cost / float64(covered)
+}
+
+// greedyRepair completes the fixed subsets of the node into a cover, Chvátal
+// style: it repeatedly adds the free subset with the best score, then drops the
+// redundant ones. Scores never decrease as subsets are added, so they are
+// re-evaluated lazily when a subset reaches the top of the queue.
+func (inst *Instance) greedyRepair(node *Node, score GreedyScore) (*Solution, error) {
 	pq := priorityqueue.New[int, float64](priorityqueue.MinHeap)
-	selectedSubset := mat.NewVecDense(inst.NumSubsets, nil)
-	selectedSubset.CloneFromVec(node.PrimalSolution.Subsets)
-
-	for i := node.FixedSubsets; i < inst.NumSubsets; i++ {
-		pq.Put(
-			i,
-			(inst.Costs.At(i, 0)+mat.Dot(selectedSubset, inst.Conflicts.RowView(i)))/mat.Sum(inst.Subsets.ColView(i)),
-		)
+	ev := inst.NewEvaluator()
+	ev.Load(node.PrimalSolution.Subsets)
+
+	for i := range inst.NumSubsets {
+		if node.isFixed(i) {
+			continue
+		}
+		if covered := ev.NewlyCovered(i); covered > 0 {
+			pq.Put(i, score.score(ev.AddDelta(i), covered))
+		}
 	}
 
-	for !inst.isFeasible(selectedSubset) {
+	for !ev.Feasible() {
 		if pq.Len() == 0 {
 			return nil, fmt.Errorf("Infeasible")
 		}
 
 		item := pq.Get()
-		selectedSubset.SetVec(item.Value, 1)
-
-		for i := range inst.NumSubsets {
-			if inst.Conflicts.At(i, item.Value) > 0 {
-				newCost := item.Priority + inst.Conflicts.At(i, item.Value)
-				if node.PrimalSolution.Subsets.At(i, 0) < 0.5 {
-					pq.Update(i, newCost/mat.Sum(inst.Subsets.ColView(i)))
-				}
-			}
+		covered := ev.NewlyCovered(item.Value)
+		if covered == 0 {
+			continue
+		}
+		if s := score.score(ev.AddDelta(item.Value), covered); s > item.Priority+eps {
+			pq.Put(item.Value, s)
+			continue
 		}
+		ev.Add(item.Value)
 	}
 
-	return &Solution{
-		Subsets:   selectedSubset,
-		TotalCost: inst.getCost(selectedSubset),
-	}, nil
+	inst.removeRedundant(ev, node)
+	return ev.Solution(), nil
+}
+
+// removeRedundant drops from the cover the free subsets whose elements are all
+// covered by other subsets, the most expensive first.
+func (inst *Instance) removeRedundant(ev *Evaluator, node *Node) {
+	selected := make([]int, 0)
+	for i := range inst.NumSubsets {
+		if ev.Selected(i) && !node.isFixed(i) {
+			selected = append(selected, i)
+		}
+	}
+	slices.SortStableFunc(selected, func(a, b int) int {
+		return cmp.Compare(ev.RemoveDelta(a), ev.RemoveDelta(b))
+	})
+	for _, i := range selected {
+		if ev.Uncovers(i) == 0 {
+			ev.Remove(i)
+		}
+	}
 }
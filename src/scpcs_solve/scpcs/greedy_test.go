@@ -0,0 +1,138 @@
+package scpcs
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// randomInstance returns the native text of an instance whose elements are
+// each covered by one to four random subsets.
+func randomInstance(rng *rand.Rand, numElements, numSubsets int) string {
+	s := new(strings.Builder)
+	fmt.Fprintln(s, numElements, numSubsets)
+	for i := range numSubsets {
+		if i > 0 {
+			s.WriteString(" ")
+		}
+		fmt.Fprintf(s, "%.3f", 1+9*rng.Float64())
+	}
+	s.WriteString("\n")
+	for range numElements {
+		subsets := rng.Perm(numSubsets)[:1+rng.Intn(4)]
+		fmt.Fprint(s, len(subsets))
+		for _, i := range subsets {
+			fmt.Fprint(s, " ", i+1)
+		}
+		s.WriteString("\n")
+	}
+	return s.String()
+}
+
+// fixedNode returns a restored node with the subsets in selected and those in
+// excluded fixed.
+func fixedNode(inst *Instance, selected, excluded []int) *Node {
+	node := &Node{fixings: newBitset(len(selected) + len(excluded))}
+	for k, i := range slices.Concat(selected, excluded) {
+		node.fixed = append(node.fixed, int32(i))
+		if k < len(selected) {
+			node.fixings.set(k)
+		}
+	}
+	node.restore(inst)
+	return node
+}
+
+// eagerRepair is the greedy repair re-evaluating every score at each step.
+func (inst *Instance) eagerRepair(node *Node, score GreedyScore) *Solution {
+	ev := inst.NewEvaluator()
+	ev.Load(node.PrimalSolution.Subsets)
+	for !ev.Feasible() {
+		best, bestScore := -1, 0.0
+		for i := range inst.NumSubsets {
+			if node.isFixed(i) || ev.Selected(i) {
+				continue
+			}
+			if covered := ev.NewlyCovered(i); covered > 0 {
+				if s := score.score(ev.AddDelta(i), covered); best < 0 || s < bestScore {
+					best, bestScore = i, s
+				}
+			}
+		}
+		if best < 0 {
+			return nil
+		}
+		ev.Add(best)
+	}
+	inst.removeRedundant(ev, node)
+	return ev.Solution()
+}
+
+func TestGreedyRepair(t *testing.T) {
+	tests := []struct {
+		elements, subsets int
+		selected          []int
+		excluded          []int
+	}{
+		{8, 6, nil, nil},
+		{20, 15, nil, nil},
+		{20, 15, []int{0, 7}, nil},
+		{30, 25, nil, []int{1, 2, 3}},
+		{30, 25, []int{4}, []int{5, 6, 7, 8}},
+		{50, 40, []int{10, 20}, []int{0, 1, 2, 3, 4, 5}},
+	}
+	for _, score := range []GreedyScore{ScoreRatio, ScoreCostLog, ScoreCostSqrt} {
+		for k, tt := range tests {
+			for seed := range int64(5) {
+				name := fmt.Sprintf("%v/%d/%d", score, k, seed)
+				inst := parseTestInstance(t, randomInstance(rand.New(rand.NewSource(seed)), tt.elements, tt.subsets), LoadOptions{})
+				node := fixedNode(inst, tt.selected, tt.excluded)
+
+				want := inst.eagerRepair(node, score)
+				sol, err := inst.greedyRepair(node, score)
+				if want == nil {
+					if err == nil {
+						t.Errorf("%v: repaired a node without covers", name)
+					}
+					continue
+				}
+				if err != nil {
+					t.Errorf("%v: %v", name, err)
+					continue
+				}
+
+				ev := inst.NewEvaluator()
+				ev.Load(sol.Subsets)
+				if !ev.Feasible() {
+					t.Errorf("%v: %d elements uncovered", name, ev.Uncovered())
+				}
+				for _, i := range tt.selected {
+					if !ev.Selected(i) {
+						t.Errorf("%v: subset %d fixed in but not selected", name, i)
+					}
+				}
+				for _, i := range tt.excluded {
+					if ev.Selected(i) {
+						t.Errorf("%v: subset %d fixed out but selected", name, i)
+					}
+				}
+				for i := range inst.NumSubsets {
+					if ev.Selected(i) && !node.isFixed(i) && ev.Uncovers(i) == 0 {
+						t.Errorf("%v: subset %d is redundant", name, i)
+					}
+				}
+				if !almostEqual(sol.TotalCost, ev.Cost()) {
+					t.Errorf("%v: got cost %v, want %v", name, sol.TotalCost, ev.Cost())
+				}
+				for i := range inst.NumSubsets {
+					if sol.Subsets.AtVec(i) != want.Subsets.AtVec(i) {
+						t.Errorf("%v: lazy scores picked\n%v\nwant eager\n%v", name, sol, want)
+						break
+					}
+				}
+			}
+		}
+	}
+}
@@ -7,8 +7,20 @@ import (
 	"gonum.org/v1/gonum/mat"
 )
 
-func (inst *Instance) runHighsSolver(lp *highs.Model) (*Solution, error) {
-	solution, err := lp.Solve()
+func (inst *Instance) runHighsSolver(lp *highs.Model, threads int) (*Solution, error) {
+	raw, err := lp.ToRawModel()
+	if err != nil {
+		return nil, err
+	}
+	err = raw.SetBoolOption("output_flag", false)
+	if err != nil {
+		return nil, err
+	}
+	err = raw.SetIntOption("threads", max(threads, 1))
+	if err != nil {
+		return nil, err
+	}
+	solution, err := raw.Solve()
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +54,12 @@ func (inst *Instance) defBaseSCP(lp *highs.Model) {
 
 	lp.ColCosts = row
 	for i := range inst.NumElements {
-		lp.AddDenseRow(1, inst.Subsets.RawRowView(i), float64(inst.NumElements))
+		r := len(lp.RowLower)
+		for _, j := range inst.Subsets.Row(i) {
+			lp.ConstMatrix = append(lp.ConstMatrix, highs.Nonzero{Row: r, Col: j, Val: 1})
+		}
+		lp.RowLower = append(lp.RowLower, 1)
+		lp.RowUpper = append(lp.RowUpper, float64(inst.NumElements))
 	}
 }
 
@@ -72,7 +89,7 @@ func (inst *Instance) defSCPCS() *highs.Model {
 	return lp
 }
 
-func (inst *Instance) Solve() (*Solution, error) {
+func (inst *Instance) Solve(opts SolveOptions) (*Solution, error) {
 	lp := inst.defSCPCS()
-	return inst.runHighsSolver(lp)
+	return inst.runHighsSolver(lp, opts.HighsThreads)
 }
@@ -0,0 +1,156 @@
+package scpcs
+
+import (
+	"slices"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Incidence is the sparse element-subset incidence matrix of an instance,
+// stored both by rows (CSR), listing the subsets covering each element, and by
+// columns (CSC), listing the elements of each subset. Lists are sorted.
+// Incidence implements mat.Matrix, but the algorithms go through the row and
+// column lists.
+type Incidence struct {
+	numRows  int
+	numCols  int
+	rowStart []int
+	rowIndex []int
+	colStart []int
+	colIndex []int
+}
+
+// NewIncidence builds the incidence matrix from the list of the subsets
+// covering each element.
+func NewIncidence(numRows, numCols int, rows [][]int) *Incidence {
+	a := &Incidence{numRows: numRows, numCols: numCols}
+	a.rowStart, a.rowIndex = compress(rows)
+	a.colStart, a.colIndex = compress(transposeLists(rows, numCols))
+	return a
+}
+
+func newIncidenceFromCols(numRows, numCols int, cols [][]int) *Incidence {
+	a := &Incidence{numRows: numRows, numCols: numCols}
+	a.colStart, a.colIndex = compress(cols)
+	a.rowStart, a.rowIndex = compress(transposeLists(cols, numRows))
+	return a
+}
+
+func compress(lists [][]int) (start, index []int) {
+	start = make([]int, len(lists)+1)
+	for i, l := range lists {
+		start[i+1] = start[i] + len(l)
+	}
+	index = make([]int, 0, start[len(lists)])
+	for _, l := range lists {
+		sorted := slices.Clone(l)
+		slices.Sort(sorted)
+		index = append(index, sorted...)
+	}
+	return
+}
+
+func transposeLists(lists [][]int, n int) [][]int {
+	counts := make([]int, n)
+	for _, l := range lists {
+		for _, j := range l {
+			counts[j]++
+		}
+	}
+	transposed := make([][]int, n)
+	for j := range transposed {
+		transposed[j] = make([]int, 0, counts[j])
+	}
+	for i, l := range lists {
+		for _, j := range l {
+			transposed[j] = append(transposed[j], i)
+		}
+	}
+	return transposed
+}
+
+// Row returns the subsets covering element i. The slice must not be modified.
+func (a *Incidence) Row(i int) []int {
+	return a.rowIndex[a.rowStart[i]:a.rowStart[i+1]]
+}
+
+// Col returns the elements of subset j. The slice must not be modified.
+func (a *Incidence) Col(j int) []int {
+	return a.colIndex[a.colStart[j]:a.colStart[j+1]]
+}
+
+func (a *Incidence) NNZ() int {
+	return len(a.rowIndex)
+}
+
+func (a *Incidence) Dims() (r, c int) {
+	return a.numRows, a.numCols
+}
+
+func (a *Incidence) At(i, j int) float64 {
+	if _, found := slices.BinarySearch(a.Row(i), j); found {
+		return 1
+	}
+	return 0
+}
+
+func (a *Incidence) T() mat.Matrix {
+	return mat.Transpose{Matrix: a}
+}
+
+// Dense returns a dense copy of the matrix, for compatibility with code
+// expecting the elements x subsets mat.Dense.
+func (a *Incidence) Dense() *mat.Dense {
+	d := mat.NewDense(max(1, a.numRows), max(1, a.numCols), nil)
+	for i := range a.numRows {
+		for _, j := range a.Row(i) {
+			d.Set(i, j, 1)
+		}
+	}
+	return d
+}
+
+// MulVecTo stores A x in dst, which has one entry per element.
+func (a *Incidence) MulVecTo(dst *mat.VecDense, x mat.Vector) {
+	for i := range a.numRows {
+		sum := 0.0
+		for _, j := range a.Row(i) {
+			sum += x.AtVec(j)
+		}
+		dst.SetVec(i, sum)
+	}
+}
+
+// MulTransVecTo stores A^T y in dst, which has one entry per subset.
+func (a *Incidence) MulTransVecTo(dst *mat.VecDense, y mat.Vector) {
+	for j := range a.numCols {
+		sum := 0.0
+		for _, i := range a.Col(j) {
+			sum += y.AtVec(i)
+		}
+		dst.SetVec(j, sum)
+	}
+}
+
+// listBuilder collects sparse lists, rejecting duplicates. All the entries of
+// a list must be added before moving to the next one.
+type listBuilder struct {
+	lists [][]int
+	stamp []int
+}
+
+func newListBuilder(numLists, numValues int) *listBuilder {
+	return &listBuilder{
+		lists: make([][]int, numLists),
+		stamp: make([]int, numValues),
+	}
+}
+
+func (b *listBuilder) add(list, value int) bool {
+	if b.stamp[value] == list+1 {
+		return false
+	}
+	b.stamp[value] = list + 1
+	b.lists[list] = append(b.lists[list], value)
+	return true
+}
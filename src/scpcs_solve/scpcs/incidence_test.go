@@ -0,0 +1,83 @@
+package scpcs
+
+import (
+	"slices"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestIncidence(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]int
+		cols int
+	}{
+		{"single", [][]int{{0}}, 1},
+		{"unsorted", [][]int{{3, 1, 0}, {2}, {1, 3}}, 4},
+		{"empty column", [][]int{{0, 2}, {2}}, 3},
+		{"empty row", [][]int{{1}, {}, {0, 1}}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			numRows := len(tt.rows)
+			a := NewIncidence(numRows, tt.cols, tt.rows)
+			b := newIncidenceFromCols(numRows, tt.cols, transposeLists(tt.rows, tt.cols))
+
+			nnz := 0
+			for i, row := range tt.rows {
+				want := slices.Sorted(slices.Values(row))
+				if !slices.Equal(a.Row(i), want) || !slices.Equal(b.Row(i), want) {
+					t.Errorf("row %d: got %v and %v, want %v", i, a.Row(i), b.Row(i), want)
+				}
+				nnz += len(row)
+			}
+			if a.NNZ() != nnz || b.NNZ() != nnz {
+				t.Errorf("got %d and %d nonzeros, want %d", a.NNZ(), b.NNZ(), nnz)
+			}
+
+			dense := mat.NewDense(numRows, tt.cols, nil)
+			for i, row := range tt.rows {
+				for _, j := range row {
+					dense.Set(i, j, 1)
+				}
+			}
+			for j := range tt.cols {
+				var want []int
+				for i := range numRows {
+					if dense.At(i, j) == 1 {
+						want = append(want, i)
+					}
+				}
+				if !slices.Equal(a.Col(j), want) || !slices.Equal(b.Col(j), want) {
+					t.Errorf("column %d: got %v and %v, want %v", j, a.Col(j), b.Col(j), want)
+				}
+			}
+			if !mat.Equal(a, dense) || !mat.Equal(a.Dense(), dense) || !mat.Equal(a.T(), dense.T()) {
+				t.Errorf("got matrix %v, want %v", mat.Formatted(a), mat.Formatted(dense))
+			}
+
+			x := mat.NewVecDense(tt.cols, nil)
+			for j := range tt.cols {
+				x.SetVec(j, float64(j+1))
+			}
+			got, want := mat.NewVecDense(numRows, nil), mat.NewVecDense(numRows, nil)
+			a.MulVecTo(got, x)
+			want.MulVec(dense, x)
+			if !mat.Equal(got, want) {
+				t.Errorf("A x: got %v, want %v", mat.Formatted(got.T()), mat.Formatted(want.T()))
+			}
+
+			y := mat.NewVecDense(numRows, nil)
+			for i := range numRows {
+				y.SetVec(i, float64(2*i+1))
+			}
+			got, want = mat.NewVecDense(tt.cols, nil), mat.NewVecDense(tt.cols, nil)
+			a.MulTransVecTo(got, y)
+			want.MulVec(dense.T(), y)
+			if !mat.Equal(got, want) {
+				t.Errorf("A^T y: got %v, want %v", mat.Formatted(got.T()), mat.Formatted(want.T()))
+			}
+		})
+	}
+}
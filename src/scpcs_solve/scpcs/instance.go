@@ -1,129 +1,171 @@
 package scpcs
 
 import (
-	"bufio"
 	"fmt"
-	"math"
-	"os"
-	"strconv"
-	"strings"
 
 	"gonum.org/v1/gonum/mat"
 )
 
-func errorCoalesce(args ...error) error {
-	for _, e := range args {
-		if e != nil {
-			return e
-		}
-	}
-	return nil
-}
-
 func (inst *Instance) getCost(selected *mat.VecDense) (cost float64) {
 	cost = mat.Dot(selected, inst.Costs)
 	for i := range inst.NumSubsets {
-		for j := i + 1; j < inst.NumSubsets; j++ {
-			if selected.At(i, 0) > 0.5 && selected.At(j, 0) > 0.5 {
-				cost += inst.Conflicts.At(i, j)
+		if selected.AtVec(i) <= 0.5 {
+			continue
+		}
+		neighbors, penalties := inst.Conflicts.Neighbors(i)
+		for k, j := range neighbors {
+			if j > i && selected.AtVec(j) > 0.5 {
+				cost += penalties[k]
 			}
 		}
 	}
 	return
 }
 
-func (inst *Instance) parseFirstLine(scanner *bufio.Scanner) error {
-	scanner.Scan()
-	line := strings.Fields(scanner.Text())
-	numElements, err := strconv.Atoi(line[0])
+func (inst *Instance) allocate(numElements, numSubsets int) {
+	inst.NumElements = numElements
+	inst.NumSubsets = numSubsets
+	inst.Costs = mat.NewVecDense(numSubsets, nil)
+}
+
+func (inst *Instance) parseFirstLine(tr *tokenReader) error {
+	line, err := tr.nextLine("header")
 	if err != nil {
-		return fmt.Errorf("error while parsing first line: %v", err)
+		return err
+	}
+	if len(line) != 2 {
+		return tr.errorf(ErrSyntax, "expected header \"elements subsets\", found %d fields", len(line))
 	}
-	numSubsets, err := strconv.Atoi(line[1])
+	numElements, err := tr.parseSize(line[0], "number of elements")
 	if err != nil {
-		return fmt.Errorf("error while parsing first line: %v", err)
+		return err
+	}
+	numSubsets, err := tr.parseSize(line[1], "number of subsets")
+	if err != nil {
+		return err
 	}
 
-	inst.NumElements = numElements
-	inst.NumSubsets = numSubsets
-	inst.Subsets = mat.NewDense(inst.NumElements, numSubsets, nil)
-	inst.Costs = mat.NewVecDense(numSubsets, nil)
-
+	inst.allocate(numElements, numSubsets)
 	return nil
 }
 
-func (inst *Instance) parseSecondLine(scanner *bufio.Scanner) error {
-	scanner.Scan()
-	line := strings.Fields(scanner.Text())
+func (inst *Instance) parseSecondLine(tr *tokenReader) error {
+	line, err := tr.nextLine("subset costs")
+	if err != nil {
+		return err
+	}
+	if len(line) != inst.NumSubsets {
+		return tr.errorf(ErrCountMismatch, "found %d costs for %d subsets", len(line), inst.NumSubsets)
+	}
 	for i, tok := range line {
-		v, err := strconv.Atoi(tok)
+		cost, err := tr.parseCost(tok)
 		if err != nil {
-			return fmt.Errorf("error while parsing second line: %v", err)
+			return err
 		}
-		inst.Costs.SetVec(i, float64(v))
+		inst.Costs.SetVec(i, cost)
 	}
 	return nil
 }
 
-func (inst *Instance) parseIncompSets(scanner *bufio.Scanner) error {
-	i := 0
-	for scanner.Scan() {
-		line := strings.Fields(scanner.Text())[1:]
-		for _, tok := range line {
-			v, err := strconv.Atoi(tok)
+func (inst *Instance) parseIncompSets(tr *tokenReader) error {
+	rows := newListBuilder(inst.NumElements, inst.NumSubsets)
+	for i := range inst.NumElements {
+		line, err := tr.nextLine(fmt.Sprintf("subsets of element %d", i+1))
+		if err != nil {
+			return err
+		}
+		size, err := tr.parseCount(line[0], "row size")
+		if err != nil {
+			return err
+		}
+		if size != len(line)-1 {
+			return tr.errorf(ErrCountMismatch, "element %d declares %d subsets but lists %d", i+1, size, len(line)-1)
+		}
+		for _, tok := range line[1:] {
+			j, err := tr.parseIndex(tok, "subset index", inst.NumSubsets)
 			if err != nil {
-				return fmt.Errorf("error while parsing incompatibility set %d: %v", i, err)
+				return err
+			}
+			if !rows.add(i, j) {
+				return tr.errorf(ErrDuplicate, "subset %d listed twice for element %d", j+1, i+1)
 			}
-			inst.Subsets.Set(i, v-1, 1)
 		}
-		i++
 	}
+	inst.Subsets = NewIncidence(inst.NumElements, inst.NumSubsets, rows.lists)
 	return nil
 }
 
-func (inst *Instance) computeConflicts(conflictThreshold int) error {
-	inst.Conflicts = mat.NewDense(inst.NumSubsets, inst.NumSubsets, nil)
-	inst.ConflictsList = make([][]int, 0)
-	coeffs := mat.NewVecDense(inst.NumSubsets, nil)
-	for i := range inst.NumSubsets {
-		coeffs.SetVec(i, inst.Costs.At(i, 0)/mat.Sum(inst.Subsets.ColView(i)))
+func (inst *Instance) parseNative(tr *tokenReader) error {
+	if err := inst.parseFirstLine(tr); err != nil {
+		return err
 	}
-	coeff := math.Round(mat.Max(coeffs))
-	if coeff == 0 {
-		coeff = 1
+	if err := inst.parseSecondLine(tr); err != nil {
+		return err
 	}
-	for i := range inst.NumSubsets {
-		for j := i + 1; j < inst.NumSubsets; j++ {
-			intsersectionSize := mat.Dot(inst.Subsets.ColView(i), (inst.Subsets.ColView(j)))
-			conflictSize := math.Round(intsersectionSize) - float64(conflictThreshold)
-			if conflictSize > eps {
-				conflictCost := coeff * conflictSize
-				inst.Conflicts.Set(i, j, float64(conflictCost))
-				inst.Conflicts.Set(j, i, float64(conflictCost))
-				inst.ConflictsList = append(inst.ConflictsList, []int{i, j})
-			}
-		}
+	if err := inst.parseIncompSets(tr); err != nil {
+		return err
 	}
-	return nil
+	return inst.parseConflictsSection(tr)
 }
 
-func LoadInstance(filename string, conflictThreshold int) (*Instance, error) {
-	inst := new(Instance)
-	file, err := os.Open(filename)
+// parseConflictsSection reads the optional trailing section of an instance
+// file, made of a "conflicts <count>" line followed by <count> lines of
+// "i j penalty" triples with 1-based subset indices. When the section is
+// present the conflicts are taken as given instead of being derived from the
+// subsets intersections.
+func (inst *Instance) parseConflictsSection(tr *tokenReader) error {
+	eof, err := tr.atEOF()
+	if err != nil || eof {
+		return err
+	}
+	tok, err := tr.next("conflicts section")
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if tok != "conflicts" {
+		return tr.errorf(ErrSyntax, "unexpected \"%v\" after the last element, expected conflicts section", tok)
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	err = errorCoalesce(
-		inst.parseFirstLine(scanner),
-		inst.parseSecondLine(scanner),
-		inst.parseIncompSets(scanner),
-		inst.computeConflicts(conflictThreshold),
-	)
+	numConflicts, err := tr.nextCount("number of conflicts")
 	if err != nil {
-		return nil, err
+		return err
+	}
+	inst.initConflicts()
+	for range numConflicts {
+		i, err := tr.nextIndex("subset index", inst.NumSubsets)
+		if err != nil {
+			return err
+		}
+		j, err := tr.nextIndex("subset index", inst.NumSubsets)
+		if err != nil {
+			return err
+		}
+		penalty, err := tr.nextFloat("penalty")
+		if err != nil {
+			return err
+		}
+		if i == j {
+			return tr.errorf(ErrInvalidValue, "subset %d in conflict with itself", i+1)
+		}
+		if penalty <= 0 {
+			return tr.errorf(ErrInvalidValue, "penalty must be positive, found %v", penalty)
+		}
+		if !inst.setConflict(i, j, penalty) {
+			return tr.errorf(ErrDuplicate, "conflict between subsets %d and %d given twice", i+1, j+1)
+		}
+	}
+	return tr.expectEOF("the last conflict")
+}
+
+func (inst *Instance) initConflicts() {
+	inst.Conflicts = NewConflictGraph(inst.NumSubsets)
+	inst.ConflictsList = make([][]int, 0)
+}
+
+func (inst *Instance) setConflict(i, j int, penalty float64) bool {
+	if !inst.Conflicts.Add(i, j, penalty) {
+		return false
 	}
-	return inst, nil
+	inst.ConflictsList = append(inst.ConflictsList, []int{min(i, j), max(i, j)})
+	return true
 }
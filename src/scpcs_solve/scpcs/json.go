@@ -0,0 +1,210 @@
+package scpcs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// jsonInstance is the JSON encoding of an Instance. Element and subset indices
+// are 0-based. A missing or null "conflicts" field means that the conflicts
+// have to be derived from the subsets intersections, while an empty list means
+// that there are no conflicts.
+type jsonInstance struct {
+	Elements     int            `json:"elements"`
+	ElementNames []string       `json:"elementNames,omitempty"`
+	Subsets      []jsonSubset   `json:"subsets"`
+	Conflicts    []jsonConflict `json:"conflicts"`
+}
+
+type jsonSubset struct {
+	Name     string  `json:"name,omitempty"`
+	Cost     float64 `json:"cost"`
+	Elements []int   `json:"elements"`
+}
+
+type jsonConflict struct {
+	I       int     `json:"i"`
+	J       int     `json:"j"`
+	Penalty float64 `json:"penalty"`
+}
+
+// jsonSolution is the JSON encoding of a Solution. A null total cost stands for
+// an infinite cost, i.e. no feasible solution, and is also written for a NaN
+// cost, which JSON cannot represent.
+type jsonSolution struct {
+	NumSubsets int      `json:"numSubsets"`
+	Subsets    []int    `json:"subsets"`
+	TotalCost  *float64 `json:"totalCost"`
+}
+
+func (inst *Instance) toJSON() *jsonInstance {
+	ji := &jsonInstance{
+		Elements:     inst.NumElements,
+		ElementNames: inst.ElementNames,
+		Subsets:      make([]jsonSubset, inst.NumSubsets),
+		Conflicts:    make([]jsonConflict, 0, len(inst.ConflictsList)),
+	}
+	for j := range inst.NumSubsets {
+		ji.Subsets[j].Cost = inst.Costs.AtVec(j)
+		ji.Subsets[j].Elements = slices.Clone(inst.Subsets.Col(j))
+		if inst.SubsetNames != nil {
+			ji.Subsets[j].Name = inst.SubsetNames[j]
+		}
+	}
+	for _, pair := range inst.ConflictsList {
+		ji.Conflicts = append(ji.Conflicts, jsonConflict{
+			I:       pair[0],
+			J:       pair[1],
+			Penalty: inst.Conflicts.At(pair[0], pair[1]),
+		})
+	}
+	return ji
+}
+
+func jsonErrorf(kind error, format string, args ...any) error {
+	return &ParseError{Kind: kind, Msg: fmt.Sprintf(format, args...)}
+}
+
+// fromJSON converts a decoded instance, with at most maxCount elements.
+func (inst *Instance) fromJSON(ji *jsonInstance, maxCount int) error {
+	if ji.Elements <= 0 {
+		return jsonErrorf(ErrInvalidValue, "number of elements must be positive, found %d", ji.Elements)
+	}
+	if ji.Elements > maxCount {
+		return jsonErrorf(ErrInvalidValue, "number of elements %d exceeds the size of the input", ji.Elements)
+	}
+	if len(ji.Subsets) == 0 {
+		return jsonErrorf(ErrInvalidValue, "no subsets")
+	}
+	if ji.ElementNames != nil && len(ji.ElementNames) != ji.Elements {
+		return jsonErrorf(ErrCountMismatch, "%d element names given for %d elements", len(ji.ElementNames), ji.Elements)
+	}
+
+	inst.allocate(ji.Elements, len(ji.Subsets))
+	inst.ElementNames = ji.ElementNames
+	cols := newListBuilder(inst.NumSubsets, inst.NumElements)
+	for j, s := range ji.Subsets {
+		if s.Name != "" {
+			if inst.SubsetNames == nil {
+				inst.SubsetNames = make([]string, inst.NumSubsets)
+			}
+			inst.SubsetNames[j] = s.Name
+		}
+		if s.Cost < 0 {
+			return jsonErrorf(ErrInvalidValue, "subset %d: negative cost %v", j, s.Cost)
+		}
+		inst.Costs.SetVec(j, s.Cost)
+		for _, i := range s.Elements {
+			if i < 0 || i >= inst.NumElements {
+				return jsonErrorf(ErrOutOfRange, "subset %d: element %d out of range [0, %d)", j, i, inst.NumElements)
+			}
+			if !cols.add(j, i) {
+				return jsonErrorf(ErrDuplicate, "subset %d: element %d listed twice", j, i)
+			}
+		}
+	}
+	inst.Subsets = newIncidenceFromCols(inst.NumElements, inst.NumSubsets, cols.lists)
+
+	if ji.Conflicts == nil {
+		return nil
+	}
+	inst.initConflicts()
+	for k, c := range ji.Conflicts {
+		if c.I < 0 || c.I >= inst.NumSubsets || c.J < 0 || c.J >= inst.NumSubsets {
+			return jsonErrorf(ErrOutOfRange, "conflict %d: subset out of range [0, %d)", k, inst.NumSubsets)
+		}
+		if c.I == c.J {
+			return jsonErrorf(ErrInvalidValue, "conflict %d: subset %d in conflict with itself", k, c.I)
+		}
+		if c.Penalty <= 0 {
+			return jsonErrorf(ErrInvalidValue, "conflict %d: penalty must be positive", k)
+		}
+		if !inst.setConflict(c.I, c.J, c.Penalty) {
+			return jsonErrorf(ErrDuplicate, "conflict %d: subsets %d and %d already in conflict", k, c.I, c.J)
+		}
+	}
+	return nil
+}
+
+func (inst *Instance) parseJSON(r io.Reader, name string, maxCount int) error {
+	ji := new(jsonInstance)
+	if err := json.NewDecoder(r).Decode(ji); err != nil {
+		return &ParseError{File: name, Kind: ErrSyntax, Msg: err.Error()}
+	}
+	err := inst.fromJSON(ji, maxCount)
+	if perr, ok := err.(*ParseError); ok {
+		perr.File = name
+	}
+	return err
+}
+
+func (inst *Instance) MarshalJSON() ([]byte, error) {
+	return json.Marshal(inst.toJSON())
+}
+
+// UnmarshalJSON decodes an instance, deriving the conflicts with the default
+// penalty model when none are given. Use LoadInstance to choose the model.
+func (inst *Instance) UnmarshalJSON(data []byte) error {
+	ji := new(jsonInstance)
+	if err := json.Unmarshal(data, ji); err != nil {
+		return err
+	}
+	*inst = Instance{}
+	if err := inst.fromJSON(ji, len(data)); err != nil {
+		return err
+	}
+	if inst.Conflicts == nil {
+		return inst.computeConflicts(IntersectionPenalty{}, 0)
+	}
+	return nil
+}
+
+func (sol *Solution) MarshalJSON() ([]byte, error) {
+	js := &jsonSolution{
+		NumSubsets: sol.Subsets.Len(),
+		Subsets:    make([]int, 0),
+	}
+	for i := range sol.Subsets.Len() {
+		if sol.Subsets.AtVec(i) > 0.5 {
+			js.Subsets = append(js.Subsets, i)
+		}
+	}
+	if !math.IsInf(sol.TotalCost, 0) && !math.IsNaN(sol.TotalCost) {
+		js.TotalCost = &sol.TotalCost
+	}
+	return json.Marshal(js)
+}
+
+func (sol *Solution) UnmarshalJSON(data []byte) error {
+	js := new(jsonSolution)
+	if err := json.Unmarshal(data, js); err != nil {
+		return err
+	}
+	if js.NumSubsets <= 0 {
+		return fmt.Errorf("number of subsets must be positive")
+	}
+	// A solution lists only the selected subsets, so its size does not bound
+	// the number of subsets of the instance.
+	if js.NumSubsets > maxDeclaredCount {
+		return fmt.Errorf("number of subsets %d exceeds the maximum of %d", js.NumSubsets, maxDeclaredCount)
+	}
+
+	subsets := mat.NewVecDense(js.NumSubsets, nil)
+	for _, i := range js.Subsets {
+		if i < 0 || i >= js.NumSubsets {
+			return fmt.Errorf("subset %d out of range [0, %d)", i, js.NumSubsets)
+		}
+		subsets.SetVec(i, 1)
+	}
+	sol.Subsets = subsets
+	sol.TotalCost = math.Inf(1)
+	if js.TotalCost != nil {
+		sol.TotalCost = *js.TotalCost
+	}
+	return nil
+}
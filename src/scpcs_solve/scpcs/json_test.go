@@ -0,0 +1,47 @@
+package scpcs
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSolutionJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		totalCost float64
+		want      string
+		decoded   float64
+	}{
+		{"finite", 7.5, `{"numSubsets":4,"subsets":[0,2],"totalCost":7.5}`, 7.5},
+		{"infinite", math.Inf(1), `{"numSubsets":4,"subsets":[0,2],"totalCost":null}`, math.Inf(1)},
+		{"NaN", math.NaN(), `{"numSubsets":4,"subsets":[0,2],"totalCost":null}`, math.Inf(1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sol := &Solution{Subsets: mat.NewVecDense(4, []float64{1, 0, 1, 0}), TotalCost: tt.totalCost}
+			data, err := json.Marshal(sol)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("got %s, want %s", data, tt.want)
+			}
+			got := new(Solution)
+			if err := json.Unmarshal(data, got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !mat.Equal(got.Subsets, sol.Subsets) || got.TotalCost != tt.decoded {
+				t.Errorf("got %v, want subsets %v and cost %v", got, sol.Subsets.RawVector().Data, tt.decoded)
+			}
+		})
+	}
+
+	for _, data := range []string{`{"numSubsets":0,"subsets":[]}`, `{"numSubsets":2,"subsets":[2]}`, `{"numSubsets":100000000000000,"subsets":[]}`} {
+		if err := json.Unmarshal([]byte(data), new(Solution)); err == nil {
+			t.Errorf("Unmarshal accepted %s", data)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package scpcs
+
+import (
+	"sync/atomic"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// bitset is a packed set of small non-negative integers.
+type bitset []uint64
+
+func newBitset(n int) bitset {
+	return make(bitset, (n+63)/64)
+}
+
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << (i % 64)
+}
+
+func (b bitset) test(i int) bool {
+	return b[i/64]&(1<<(i%64)) != 0
+}
+
+// multipliers is a vector of Lagrangean multipliers shared by several nodes.
+// The vector is dropped when the last node holding it releases it, so that
+// the processed ancestors, still referenced by their open descendants, do not
+// keep it alive.
+type multipliers struct {
+	vec  *mat.VecDense
+	refs atomic.Int32
+}
+
+func newMultipliers(vec *mat.VecDense) *multipliers {
+	return &multipliers{vec: vec}
+}
+
+func (m *multipliers) retain() *multipliers {
+	if m != nil {
+		m.refs.Add(1)
+	}
+	return m
+}
+
+func (m *multipliers) release() {
+	if m != nil && m.refs.Add(-1) == 0 {
+		m.vec = nil
+	}
+}
+
+func (m *multipliers) vector() *mat.VecDense {
+	if m == nil {
+		return nil
+	}
+	return m.vec
+}
+
+// restore rebuilds the fixed part of the primal solution of the node from the
+// fixings along its path to the root.
+func (n *Node) restore(inst *Instance) {
+	subsets := mat.NewVecDense(inst.NumSubsets, nil)
+	n.fixedMask = newBitset(inst.NumSubsets)
+	for m := n; m != nil; m = m.parent {
+		for k, i := range m.fixed {
+			n.fixedMask.set(int(i))
+			if m.fixings.test(k) {
+				subsets.SetVec(int(i), 1)
+			}
+		}
+	}
+	n.PrimalSolution = &Solution{
+		Subsets:   subsets,
+		TotalCost: n.fixedCost,
+	}
+}
+
+// isFixed reports whether the subset is fixed in a restored node.
+func (n *Node) isFixed(i int) bool {
+	return n.fixedMask.test(i)
+}
+
+// lastFixing returns the last fixing of the node, the one that sets it apart
+// from its previous siblings.
+func (n *Node) lastFixing() fixing {
+	k := len(n.fixed) - 1
+	return fixing{int(n.fixed[k]), n.fixings.test(k)}
+}
+
+// compact drops the state rebuilt by restore, keeping only the fixings.
+func (n *Node) compact() {
+	n.PrimalSolution = nil
+	n.fixedMask = nil
+}
+
+// discard releases a processed node, which stays in memory only as the parent
+// of its open children.
+func (n *Node) discard() {
+	n.compact()
+	n.lagrangeanMul.release()
+	n.lagrangeanMul = nil
+	n.branches = branching{}
+}
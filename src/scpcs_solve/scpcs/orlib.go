@@ -0,0 +1,92 @@
+package scpcs
+
+func (inst *Instance) parseSizes(tr *tokenReader) error {
+	numElements, err := tr.nextSize("number of elements")
+	if err != nil {
+		return err
+	}
+	numSubsets, err := tr.nextSize("number of subsets")
+	if err != nil {
+		return err
+	}
+	inst.allocate(numElements, numSubsets)
+	return nil
+}
+
+// parseORLib reads the Beasley OR-Library SCP layout (scp4x-scpnrx): the
+// number of rows and columns, the column costs and, for every row, the number
+// of columns covering it followed by their indices. Costs and row lists may
+// span any number of lines.
+func (inst *Instance) parseORLib(tr *tokenReader) error {
+	if err := inst.parseSizes(tr); err != nil {
+		return err
+	}
+
+	for j := range inst.NumSubsets {
+		tok, err := tr.next("cost")
+		if err != nil {
+			return err
+		}
+		cost, err := tr.parseCost(tok)
+		if err != nil {
+			return err
+		}
+		inst.Costs.SetVec(j, cost)
+	}
+
+	rows := newListBuilder(inst.NumElements, inst.NumSubsets)
+	for i := range inst.NumElements {
+		size, err := tr.nextCount("row size")
+		if err != nil {
+			return err
+		}
+		for range size {
+			j, err := tr.nextIndex("subset index", inst.NumSubsets)
+			if err != nil {
+				return err
+			}
+			if !rows.add(i, j) {
+				return tr.errorf(ErrDuplicate, "subset %d listed twice for element %d", j+1, i+1)
+			}
+		}
+	}
+	inst.Subsets = NewIncidence(inst.NumElements, inst.NumSubsets, rows.lists)
+	return inst.parseConflictsSection(tr)
+}
+
+// parseRail reads the OR-Library rail layout: the number of rows and columns
+// followed, for every column, by its cost, its size and the rows it covers.
+func (inst *Instance) parseRail(tr *tokenReader) error {
+	if err := inst.parseSizes(tr); err != nil {
+		return err
+	}
+
+	cols := newListBuilder(inst.NumSubsets, inst.NumElements)
+	for j := range inst.NumSubsets {
+		tok, err := tr.next("cost")
+		if err != nil {
+			return err
+		}
+		cost, err := tr.parseCost(tok)
+		if err != nil {
+			return err
+		}
+		inst.Costs.SetVec(j, cost)
+
+		size, err := tr.nextCount("column size")
+		if err != nil {
+			return err
+		}
+		for range size {
+			i, err := tr.nextIndex("element index", inst.NumElements)
+			if err != nil {
+				return err
+			}
+			if !cols.add(j, i) {
+				return tr.errorf(ErrDuplicate, "element %d listed twice for subset %d", i+1, j+1)
+			}
+		}
+	}
+	inst.Subsets = newIncidenceFromCols(inst.NumElements, inst.NumSubsets, cols.lists)
+	return inst.parseConflictsSection(tr)
+}
@@ -0,0 +1,348 @@
+package scpcs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxLineSize = 64 << 20
+	// maxDeclaredCount bounds the numbers of elements and subsets declared by
+	// an input whose size is not known, like a compressed file or a pipe.
+	maxDeclaredCount = 1 << 26
+)
+
+var (
+	ErrSyntax        = errors.New("syntax error")
+	ErrInvalidValue  = errors.New("invalid value")
+	ErrOutOfRange    = errors.New("index out of range")
+	ErrDuplicate     = errors.New("duplicate entry")
+	ErrCountMismatch = errors.New("count mismatch")
+	ErrEmptyLine     = errors.New("empty line")
+	ErrTruncated     = errors.New("unexpected end of file")
+)
+
+// ParseError describes a malformed instance. Kind is one of the Err* values
+// above and can be tested with errors.Is. Line is 0 when the position is not
+// known, as for JSON instances.
+type ParseError struct {
+	File string
+	Line int
+	Kind error
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	s := new(strings.Builder)
+	if e.File != "" {
+		fmt.Fprint(s, e.File, ":")
+	}
+	if e.Line > 0 {
+		fmt.Fprint(s, e.Line, ":")
+	}
+	if s.Len() > 0 {
+		s.WriteRune(' ')
+	}
+	s.WriteString(e.Msg)
+	return s.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Kind
+}
+
+type LoadOptions struct {
+	// Name identifies the instance in the errors, LoadInstance sets it to the
+	// file name when empty.
+	Name   string
+	Format Format
+	// Penalty derives the conflicts when the instance does not list them,
+	// IntersectionPenalty when nil.
+	Penalty PenaltyModel
+	// Threads bounds the goroutines deriving the conflicts, runtime.NumCPU()
+	// when 0.
+	Threads int
+}
+
+type tokenReader struct {
+	scanner *bufio.Scanner
+	file    string
+	tokens  []string
+	line    int
+	// maxCount bounds the declared numbers of elements and subsets.
+	maxCount int
+}
+
+func newTokenReader(r io.Reader, file string, maxCount int) *tokenReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return &tokenReader{scanner: scanner, file: file, maxCount: maxCount}
+}
+
+func (tr *tokenReader) errorf(kind error, format string, args ...any) error {
+	return &ParseError{
+		File: tr.file,
+		Line: tr.line,
+		Kind: kind,
+		Msg:  fmt.Sprintf(format, args...),
+	}
+}
+
+func (tr *tokenReader) scan() (bool, error) {
+	if !tr.scanner.Scan() {
+		if err := tr.scanner.Err(); err != nil {
+			return false, &ParseError{File: tr.file, Line: tr.line + 1, Kind: ErrSyntax, Msg: err.Error()}
+		}
+		return false, nil
+	}
+	tr.line++
+	return true, nil
+}
+
+// nextLine returns the fields of the next line, which must not be empty.
+func (tr *tokenReader) nextLine(what string) ([]string, error) {
+	ok, err := tr.scan()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, tr.errorf(ErrTruncated, "expected %v, found end of file", what)
+	}
+	fields := strings.Fields(tr.scanner.Text())
+	if len(fields) == 0 {
+		return nil, tr.errorf(ErrEmptyLine, "empty line, expected %v", what)
+	}
+	return fields, nil
+}
+
+// atEOF reports whether only blank lines are left.
+func (tr *tokenReader) atEOF() (bool, error) {
+	for len(tr.tokens) == 0 {
+		ok, err := tr.scan()
+		if err != nil || !ok {
+			return !ok, err
+		}
+		tr.tokens = strings.Fields(tr.scanner.Text())
+	}
+	return false, nil
+}
+
+func (tr *tokenReader) expectEOF(after string) error {
+	eof, err := tr.atEOF()
+	if err != nil || eof {
+		return err
+	}
+	return tr.errorf(ErrSyntax, "unexpected \"%v\" after %v", tr.tokens[0], after)
+}
+
+func (tr *tokenReader) next(what string) (string, error) {
+	eof, err := tr.atEOF()
+	if err != nil {
+		return "", err
+	}
+	if eof {
+		return "", tr.errorf(ErrTruncated, "expected %v, found end of file", what)
+	}
+	tok := tr.tokens[0]
+	tr.tokens = tr.tokens[1:]
+	return tok, nil
+}
+
+func (tr *tokenReader) parseInt(tok, what string) (int, error) {
+	v, err := strconv.Atoi(tok)
+	if err != nil {
+		return 0, tr.errorf(ErrSyntax, "invalid %v \"%v\"", what, tok)
+	}
+	return v, nil
+}
+
+func (tr *tokenReader) parseCount(tok, what string) (int, error) {
+	v, err := tr.parseInt(tok, what)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 {
+		return 0, tr.errorf(ErrInvalidValue, "negative %v %d", what, v)
+	}
+	return v, nil
+}
+
+// parseSize parses a declared number of elements or subsets, which must be
+// positive and, since each of them takes at least a byte of a meaningful
+// input, at most maxCount. It keeps a malformed header from allocating more
+// than the input can fill.
+func (tr *tokenReader) parseSize(tok, what string) (int, error) {
+	v, err := tr.parseInt(tok, what)
+	if err != nil {
+		return 0, err
+	}
+	if v <= 0 {
+		return 0, tr.errorf(ErrInvalidValue, "%v must be positive, found %d", what, v)
+	}
+	if v > tr.maxCount {
+		return 0, tr.errorf(ErrInvalidValue, "%v %d exceeds the size of the input", what, v)
+	}
+	return v, nil
+}
+
+func (tr *tokenReader) parseFloat(tok, what string) (float64, error) {
+	v, err := strconv.ParseFloat(tok, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, tr.errorf(ErrSyntax, "invalid %v \"%v\"", what, tok)
+	}
+	return v, nil
+}
+
+// parseIndex converts a 1-based index in [1, n] to a 0-based one.
+func (tr *tokenReader) parseIndex(tok, what string, n int) (int, error) {
+	v, err := tr.parseInt(tok, what)
+	if err != nil {
+		return 0, err
+	}
+	if v < 1 || v > n {
+		return 0, tr.errorf(ErrOutOfRange, "%v %d out of range [1, %d]", what, v, n)
+	}
+	return v - 1, nil
+}
+
+func (tr *tokenReader) nextCount(what string) (int, error) {
+	tok, err := tr.next(what)
+	if err != nil {
+		return 0, err
+	}
+	return tr.parseCount(tok, what)
+}
+
+func (tr *tokenReader) nextSize(what string) (int, error) {
+	tok, err := tr.next(what)
+	if err != nil {
+		return 0, err
+	}
+	return tr.parseSize(tok, what)
+}
+
+func (tr *tokenReader) nextFloat(what string) (float64, error) {
+	tok, err := tr.next(what)
+	if err != nil {
+		return 0, err
+	}
+	return tr.parseFloat(tok, what)
+}
+
+func (tr *tokenReader) nextIndex(what string, n int) (int, error) {
+	tok, err := tr.next(what)
+	if err != nil {
+		return 0, err
+	}
+	return tr.parseIndex(tok, what, n)
+}
+
+func (tr *tokenReader) parseCost(tok string) (float64, error) {
+	cost, err := tr.parseFloat(tok, "cost")
+	if err != nil {
+		return 0, err
+	}
+	if cost < 0 {
+		return 0, tr.errorf(ErrInvalidValue, "negative cost %v", tok)
+	}
+	return cost, nil
+}
+
+// ParseInstance reads an instance in the format given by the options, guessing
+// it from the content when it is FormatAuto, and validates it while parsing.
+// Compressed inputs are decompressed on the fly. Malformed inputs are reported
+// with a *ParseError.
+func ParseInstance(r io.Reader, opts LoadOptions) (*Instance, error) {
+	// The size is taken before reading, as in-memory readers report the
+	// bytes left.
+	size := inputSize(r)
+	reader := bufio.NewReaderSize(r, sniffSize)
+	content, release, err := decompress(reader)
+	if err != nil {
+		return nil, &ParseError{File: opts.Name, Kind: ErrSyntax, Msg: err.Error()}
+	}
+	defer release()
+	if content != reader {
+		reader = bufio.NewReaderSize(content, sniffSize)
+	}
+
+	format := opts.Format
+	if format == FormatAuto {
+		format = sniffFormat(reader)
+	}
+	maxCount := maxDeclaredCount
+	if size > 0 && content == reader {
+		maxCount = int(size)
+	}
+
+	inst := new(Instance)
+	switch format {
+	case FormatNative:
+		err = inst.parseNative(newTokenReader(reader, opts.Name, maxCount))
+	case FormatORLib:
+		err = inst.parseORLib(newTokenReader(reader, opts.Name, maxCount))
+	case FormatRail:
+		err = inst.parseRail(newTokenReader(reader, opts.Name, maxCount))
+	case FormatJSON:
+		err = inst.parseJSON(reader, opts.Name, maxCount)
+	default:
+		err = fmt.Errorf("unsupported instance format %v", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if inst.Conflicts == nil {
+		penalty := opts.Penalty
+		if penalty == nil {
+			penalty = IntersectionPenalty{}
+		}
+		err = inst.computeConflicts(penalty, opts.Threads)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return inst, nil
+}
+
+// inputSize returns the size in bytes of a regular file or an in-memory
+// reader, and 0 when it is not known.
+func inputSize(r io.Reader) int64 {
+	switch r := r.(type) {
+	case *os.File:
+		if fi, err := r.Stat(); err == nil && fi.Mode().IsRegular() {
+			return fi.Size()
+		}
+	case interface{ Len() int }:
+		return int64(r.Len())
+	}
+	return 0
+}
+
+// LoadInstance reads an instance from a file, or from the standard input when
+// the file name is "-".
+func LoadInstance(filename string, opts LoadOptions) (*Instance, error) {
+	if filename == "-" {
+		if opts.Name == "" {
+			opts.Name = "<stdin>"
+		}
+		return ParseInstance(os.Stdin, opts)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if opts.Name == "" {
+		opts.Name = filename
+	}
+	return ParseInstance(file, opts)
+}
@@ -0,0 +1,175 @@
+package scpcs
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testInstance = `4 13
+1 2 3 4 5 6 7 8 9 10 11 12 2.5
+5 1 2 3 4 13
+4 4 5 6 7
+4 7 8 9 10
+4 10 11 12 13
+`
+
+func parseTestInstance(t *testing.T, text string, opts LoadOptions) *Instance {
+	t.Helper()
+	inst, err := ParseInstance(strings.NewReader(text), opts)
+	if err != nil {
+		t.Fatalf("ParseInstance: %v", err)
+	}
+	return inst
+}
+
+func TestParseInstanceErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		kind  error
+		line  int
+	}{
+		{"empty input", "", ErrTruncated, 0},
+		{"no elements", "0 4\n", ErrInvalidValue, 1},
+		{"no subsets", "3 0\n", ErrInvalidValue, 1},
+		{"negative size", "-3 4\n", ErrInvalidValue, 1},
+		{"size beyond cap", "1 2000000000\n", ErrInvalidValue, 1},
+		{"size beyond input", "1 50000000\n", ErrInvalidValue, 1},
+		{"short header", "3\n", ErrSyntax, 1},
+		{"bad size", "x 4\n", ErrSyntax, 1},
+		{"missing cost", "3 4\n1 2 3\n", ErrCountMismatch, 2},
+		{"bad cost", "3 4\n1 2 x 4\n", ErrSyntax, 2},
+		{"negative cost", "3 4\n1 2 -3 4\n", ErrInvalidValue, 2},
+		{"subset out of range", "3 4\n1 2 3 4\n2 1 5\n", ErrOutOfRange, 3},
+		{"duplicate subset", "3 4\n1 2 3 4\n2 1 1\n", ErrDuplicate, 3},
+		{"row size mismatch", "3 4\n1 2 3 4\n3 1 2\n", ErrCountMismatch, 3},
+		{"empty row", "3 4\n1 2 3 4\n2 1 2\n\n", ErrEmptyLine, 4},
+		{"missing row", "3 4\n1 2 3 4\n2 1 2\n2 2 3\n", ErrTruncated, 4},
+		{"trailing garbage", "3 4\n1 2 3 4\n2 1 2\n2 2 3\n1 4\nfoo\n", ErrSyntax, 6},
+		{"self conflict", "3 4\n1 2 3 4\n2 1 2\n2 2 3\n1 4\nconflicts 1\n1 1 2\n", ErrInvalidValue, 7},
+		{"zero penalty", "3 4\n1 2 3 4\n2 1 2\n2 2 3\n1 4\nconflicts 1\n1 2 0\n", ErrInvalidValue, 7},
+		{"duplicate conflict", "3 4\n1 2 3 4\n2 1 2\n2 2 3\n1 4\nconflicts 2\n1 2 3\n2 1 3\n", ErrDuplicate, 8},
+		{"missing conflict", "3 4\n1 2 3 4\n2 1 2\n2 2 3\n1 4\nconflicts 2\n1 2 3\n", ErrTruncated, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseInstance(bytes.NewReader([]byte(tt.input)), LoadOptions{Name: "test", Format: FormatNative})
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("got error %v, want a *ParseError", err)
+			}
+			if !errors.Is(err, tt.kind) {
+				t.Errorf("got kind %v, want %v (%v)", perr.Kind, tt.kind, err)
+			}
+			if perr.Line != tt.line {
+				t.Errorf("got line %d, want %d (%v)", perr.Line, tt.line, err)
+			}
+			if perr.File != "test" {
+				t.Errorf("got file %q, want \"test\"", perr.File)
+			}
+		})
+	}
+}
+
+func TestParseJSONErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		kind  error
+	}{
+		{"malformed", `{"elements": 3,`, ErrSyntax},
+		{"no elements", `{"elements": 0, "subsets": [{"cost": 1, "elements": []}]}`, ErrInvalidValue},
+		{"no subsets", `{"elements": 1, "subsets": []}`, ErrInvalidValue},
+		{"elements beyond input", `{"elements": 2000000000, "subsets": [{"cost": 1, "elements": [0]}]}`, ErrInvalidValue},
+		{"negative cost", `{"elements": 1, "subsets": [{"cost": -1, "elements": [0]}]}`, ErrInvalidValue},
+		{"element out of range", `{"elements": 1, "subsets": [{"cost": 1, "elements": [1]}]}`, ErrOutOfRange},
+		{"duplicate element", `{"elements": 1, "subsets": [{"cost": 1, "elements": [0, 0]}]}`, ErrDuplicate},
+		{"self conflict", `{"elements": 1, "subsets": [{"cost": 1, "elements": [0]}], "conflicts": [{"i": 0, "j": 0, "penalty": 1}]}`, ErrInvalidValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseInstance(strings.NewReader(tt.input), LoadOptions{Name: "test", Format: FormatJSON})
+			if !errors.Is(err, tt.kind) {
+				t.Errorf("got error %v, want kind %v", err, tt.kind)
+			}
+			if err := new(Instance).UnmarshalJSON([]byte(tt.input)); err == nil {
+				t.Errorf("UnmarshalJSON accepted the instance")
+			}
+		})
+	}
+}
+
+func TestParseInstanceFormats(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		input  string
+	}{
+		{"native", FormatNative, "3 4\n1 2 3 4\n2 1 2\n2 2 3\n1 4\n"},
+		{"orlib", FormatORLib, "3 4\n1 2\n3 4\n2\n1 2\n2\n2\n3\n1\n4\n"},
+		{"rail", FormatRail, "3 4\n1 1 1\n2 2 1 2\n3 1 2\n4 1 3\n"},
+		{"json", FormatJSON, `{"elements": 3, "subsets": [
+			{"cost": 1, "elements": [0]},
+			{"cost": 2, "elements": [0, 1]},
+			{"cost": 3, "elements": [1]},
+			{"cost": 4, "elements": [2]}]}`},
+	}
+	want := parseTestInstance(t, tests[0].input, LoadOptions{Format: FormatNative})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, format := range []Format{tt.format, FormatAuto} {
+				got := parseTestInstance(t, tt.input, LoadOptions{Format: format})
+				assertSameInstance(t, want, got)
+			}
+		})
+	}
+}
+
+func TestWriteToRoundTrip(t *testing.T) {
+	derived := parseTestInstance(t, testInstance, LoadOptions{})
+	given := parseTestInstance(t, testInstance+"conflicts 2\n1 13 4.5\n2 3 1\n", LoadOptions{})
+	for _, format := range []Format{FormatNative, FormatORLib, FormatRail, FormatJSON} {
+		for _, inst := range []*Instance{derived, given} {
+			for _, withConflicts := range []bool{false, true} {
+				if inst == given && !withConflicts {
+					continue
+				}
+				buf := new(bytes.Buffer)
+				if err := inst.WriteTo(buf, format, withConflicts); err != nil {
+					t.Fatalf("%v: WriteTo: %v", format, err)
+				}
+				got, err := ParseInstance(bytes.NewReader(buf.Bytes()), LoadOptions{})
+				if err != nil {
+					t.Fatalf("%v: ParseInstance: %v\n%s", format, err, buf)
+				}
+				assertSameInstance(t, inst, got)
+			}
+		}
+	}
+}
+
+func assertSameInstance(t *testing.T, want, got *Instance) {
+	t.Helper()
+	if got.NumElements != want.NumElements || got.NumSubsets != want.NumSubsets {
+		t.Fatalf("got %dx%d instance, want %dx%d", got.NumElements, got.NumSubsets, want.NumElements, want.NumSubsets)
+	}
+	for j := range want.NumSubsets {
+		if got.Costs.AtVec(j) != want.Costs.AtVec(j) {
+			t.Errorf("subset %d: got cost %v, want %v", j, got.Costs.AtVec(j), want.Costs.AtVec(j))
+		}
+		if !slices.Equal(got.Subsets.Col(j), want.Subsets.Col(j)) {
+			t.Errorf("subset %d: got elements %v, want %v", j, got.Subsets.Col(j), want.Subsets.Col(j))
+		}
+		for k := range want.NumSubsets {
+			if got.Conflicts.At(j, k) != want.Conflicts.At(j, k) {
+				t.Errorf("conflict %d-%d: got penalty %v, want %v", j, k, got.Conflicts.At(j, k), want.Conflicts.At(j, k))
+			}
+		}
+	}
+	if len(got.ConflictsList) != len(want.ConflictsList) {
+		t.Errorf("got %d conflicts, want %d", len(got.ConflictsList), len(want.ConflictsList))
+	}
+}
@@ -0,0 +1,190 @@
+package scpcs
+
+import (
+	"fmt"
+	"math"
+)
+
+// A PenaltyModel derives the conflicts of an instance from its subsets.
+// Penalties is called once per instance and returns the penalty for selecting
+// both subsets i and j given the size of their intersection; pairs with a
+// non-positive penalty are not in conflict. The returned function may be called
+// concurrently.
+type PenaltyModel interface {
+	Penalties(inst *Instance) func(i, j int, intersection float64) float64
+}
+
+// An IntersectingPenaltyModel tells whether it never puts disjoint subsets in
+// conflict, in which case only the pairs sharing some element are evaluated.
+type IntersectingPenaltyModel interface {
+	PenaltyModel
+	OnlyIntersecting() bool
+}
+
+// IntersectionPenalty charges Weight for every element shared beyond
+// Threshold. It is the default model.
+type IntersectionPenalty struct {
+	Threshold float64
+	Weight    float64
+}
+
+// JaccardPenalty puts in conflict the subsets whose Jaccard similarity exceeds
+// Threshold, charging Weight for every shared element.
+type JaccardPenalty struct {
+	Threshold float64
+	Weight    float64
+}
+
+// OverlapPenalty puts in conflict the subsets sharing more than a Threshold
+// fraction of the smaller one, charging Weight for every shared element.
+type OverlapPenalty struct {
+	Threshold float64
+	Weight    float64
+}
+
+// ConstantPenalty charges Weight for any intersection larger than Threshold.
+type ConstantPenalty struct {
+	Threshold float64
+	Weight    float64
+}
+
+// QuadraticPenalty charges Weight times the square of the number of elements
+// shared beyond Threshold.
+type QuadraticPenalty struct {
+	Threshold float64
+	Weight    float64
+}
+
+// CostPenalty charges Weight times the sum of the two subset costs for any
+// intersection larger than Threshold. Weight defaults to 1.
+type CostPenalty struct {
+	Threshold float64
+	Weight    float64
+}
+
+var penaltyModelNames = []string{"intersection", "jaccard", "overlap", "constant", "quadratic", "cost"}
+
+// ParsePenaltyModel builds a model from its name. A zero weight selects the
+// default weight of the model, which is the largest cost per element of a
+// subset, rounded, for all the models but CostPenalty.
+func ParsePenaltyModel(name string, threshold, weight float64) (PenaltyModel, error) {
+	switch name {
+	case "intersection":
+		return IntersectionPenalty{threshold, weight}, nil
+	case "jaccard":
+		return JaccardPenalty{threshold, weight}, nil
+	case "overlap":
+		return OverlapPenalty{threshold, weight}, nil
+	case "constant":
+		return ConstantPenalty{threshold, weight}, nil
+	case "quadratic":
+		return QuadraticPenalty{threshold, weight}, nil
+	case "cost":
+		return CostPenalty{threshold, weight}, nil
+	}
+	return nil, fmt.Errorf("unknown penalty model \"%v\", expected one of %v", name, penaltyModelNames)
+}
+
+func (inst *Instance) subsetSizes() []float64 {
+	sizes := make([]float64, inst.NumSubsets)
+	for j := range inst.NumSubsets {
+		sizes[j] = float64(len(inst.Subsets.Col(j)))
+	}
+	return sizes
+}
+
+// unitPenalty is the largest cost per element of a subset, rounded and at
+// least 1.
+func (inst *Instance) unitPenalty() float64 {
+	sizes := inst.subsetSizes()
+	coeff := 0.0
+	for j := range inst.NumSubsets {
+		if sizes[j] > 0 {
+			coeff = math.Max(coeff, inst.Costs.AtVec(j)/sizes[j])
+		}
+	}
+	coeff = math.Round(coeff)
+	if coeff == 0 {
+		coeff = 1
+	}
+	return coeff
+}
+
+func defaultWeight(inst *Instance, weight float64) float64 {
+	if weight == 0 {
+		return inst.unitPenalty()
+	}
+	return weight
+}
+
+func (m IntersectionPenalty) Penalties(inst *Instance) func(i, j int, intersection float64) float64 {
+	weight := defaultWeight(inst, m.Weight)
+	return func(i, j int, intersection float64) float64 {
+		return weight * (math.Round(intersection) - m.Threshold)
+	}
+}
+
+func (m JaccardPenalty) Penalties(inst *Instance) func(i, j int, intersection float64) float64 {
+	weight := defaultWeight(inst, m.Weight)
+	sizes := inst.subsetSizes()
+	return func(i, j int, intersection float64) float64 {
+		union := sizes[i] + sizes[j] - intersection
+		if union == 0 || intersection/union <= m.Threshold {
+			return 0
+		}
+		return weight * intersection
+	}
+}
+
+func (m OverlapPenalty) Penalties(inst *Instance) func(i, j int, intersection float64) float64 {
+	weight := defaultWeight(inst, m.Weight)
+	sizes := inst.subsetSizes()
+	return func(i, j int, intersection float64) float64 {
+		smaller := math.Min(sizes[i], sizes[j])
+		if smaller == 0 || intersection/smaller <= m.Threshold {
+			return 0
+		}
+		return weight * intersection
+	}
+}
+
+func (m ConstantPenalty) Penalties(inst *Instance) func(i, j int, intersection float64) float64 {
+	weight := defaultWeight(inst, m.Weight)
+	return func(i, j int, intersection float64) float64 {
+		if math.Round(intersection) <= m.Threshold {
+			return 0
+		}
+		return weight
+	}
+}
+
+func (m QuadraticPenalty) Penalties(inst *Instance) func(i, j int, intersection float64) float64 {
+	weight := defaultWeight(inst, m.Weight)
+	return func(i, j int, intersection float64) float64 {
+		excess := math.Round(intersection) - m.Threshold
+		if excess <= 0 {
+			return 0
+		}
+		return weight * excess * excess
+	}
+}
+
+func (m CostPenalty) Penalties(inst *Instance) func(i, j int, intersection float64) float64 {
+	weight := m.Weight
+	if weight == 0 {
+		weight = 1
+	}
+	return func(i, j int, intersection float64) float64 {
+		if math.Round(intersection) <= m.Threshold {
+			return 0
+		}
+		return weight * (inst.Costs.AtVec(i) + inst.Costs.AtVec(j))
+	}
+}
+
+func (m IntersectionPenalty) OnlyIntersecting() bool { return m.Threshold >= 0 }
+func (m JaccardPenalty) OnlyIntersecting() bool      { return true }
+func (m OverlapPenalty) OnlyIntersecting() bool      { return true }
+func (m ConstantPenalty) OnlyIntersecting() bool     { return m.Threshold >= 0 }
+func (m QuadraticPenalty) OnlyIntersecting() bool    { return m.Threshold >= 0 }
+func (m CostPenalty) OnlyIntersecting() bool         { return m.Threshold >= 0 }
@@ -0,0 +1,36 @@
+package scpcs
+
+import (
+	"sync"
+)
+
+// workerPool runs tasks on a fixed number of goroutines. Tasks receive the
+// index of the worker running them.
+type workerPool struct {
+	tasks chan func(worker int)
+	wg    sync.WaitGroup
+}
+
+func newWorkerPool(workers int) *workerPool {
+	p := &workerPool{tasks: make(chan func(worker int))}
+	for w := range workers {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for task := range p.tasks {
+				task(w)
+			}
+		}()
+	}
+	return p
+}
+
+// submit blocks until a worker is free to run the task.
+func (p *workerPool) submit(task func(worker int)) {
+	p.tasks <- task
+}
+
+func (p *workerPool) close() {
+	close(p.tasks)
+	p.wg.Wait()
+}
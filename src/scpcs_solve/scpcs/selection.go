@@ -0,0 +1,190 @@
+package scpcs
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// NodeSelection is the order in which the branch and bound explores the open
+// nodes.
+type NodeSelection int
+
+const (
+	// DepthFirst explores the children of the last node first, the ones with
+	// the lowest bound first.
+	DepthFirst NodeSelection = iota
+	// BestFirst explores the node with the lowest dual bound.
+	BestFirst
+	// BreadthFirst explores the nodes level by level.
+	BreadthFirst
+	// BestEstimate explores the node whose subtree is expected to hold the
+	// best solution, estimated by the cost of its greedy repair.
+	BestEstimate
+	// Hybrid dives depth-first and periodically jumps to the node with the
+	// lowest dual bound.
+	Hybrid
+)
+
+var nodeSelectionNames = []string{
+	DepthFirst:   "depth-first",
+	BestFirst:    "best-first",
+	BreadthFirst: "breadth-first",
+	BestEstimate: "best-estimate",
+	Hybrid:       "hybrid",
+}
+
+func (s NodeSelection) String() string {
+	if s < 0 || int(s) >= len(nodeSelectionNames) {
+		return fmt.Sprintf("NodeSelection(%d)", int(s))
+	}
+	return nodeSelectionNames[s]
+}
+
+func ParseNodeSelection(s string) (NodeSelection, error) {
+	i := slices.Index(nodeSelectionNames, s)
+	if i < 0 {
+		return 0, fmt.Errorf("unknown node selection \"%v\", expected one of %v", s, nodeSelectionNames)
+	}
+	return NodeSelection(i), nil
+}
+
+func byDualBound(x, y *Node) bool {
+	return x.DualBound < y.DualBound
+}
+
+func isOpen(n *Node) bool {
+	return !n.closed
+}
+
+func isClosed(n *Node) bool {
+	return n.closed
+}
+
+func newNodeDeque(opts SolveOptions) Deque[*Node] {
+	switch opts.NodeSelection {
+	case BestFirst:
+		return NewPriorityQueue(byDualBound)
+	case BreadthFirst:
+		return NewQueue[*Node]()
+	case BestEstimate:
+		return NewPriorityQueue(func(x, y *Node) bool {
+			return x.Estimate < y.Estimate || x.Estimate == y.Estimate && x.DualBound < y.DualBound
+		})
+	case Hybrid:
+		return newHybridDeque(opts.JumpInterval, opts.JumpGap)
+	}
+	return NewStack[*Node]()
+}
+
+// hybridDeque pops the nodes depth-first, but jumps to the open node with the
+// lowest dual bound after jumpInterval nodes, or as soon as the bound of the
+// node on top of the stack exceeds the lowest one by more than jumpGap,
+// relative to the lowest. A zero jumpInterval or jumpGap disables that kind of
+// jump. Each node is kept both in the stack and in the heap, and removed
+// lazily from the one it was not popped from, both being compacted once the
+// closed nodes outnumber the open ones.
+type hybridDeque struct {
+	stack        []*Node
+	best         *PriorityQueue[*Node]
+	size         int
+	jumpInterval int
+	jumpGap      float64
+	sinceJump    int
+}
+
+func newHybridDeque(jumpInterval int, jumpGap float64) *hybridDeque {
+	return &hybridDeque{
+		best:         NewPriorityQueue(byDualBound),
+		jumpInterval: jumpInterval,
+		jumpGap:      jumpGap,
+	}
+}
+
+func (d *hybridDeque) Push(n *Node) {
+	d.stack = append(d.stack, n)
+	d.best.Push(n)
+	d.size++
+}
+
+func (d *hybridDeque) Pop() *Node {
+	for len(d.stack) > 0 && d.stack[len(d.stack)-1].closed {
+		d.stack = d.stack[:len(d.stack)-1]
+	}
+	for d.best.Size() > 0 && d.best.Peek().closed {
+		d.best.Pop()
+	}
+	if d.size == 0 {
+		return nil
+	}
+	d.size--
+
+	top := d.stack[len(d.stack)-1]
+	lowest := d.best.Peek()
+	d.sinceJump++
+	jump := d.jumpInterval > 0 && d.sinceJump >= d.jumpInterval
+	if d.jumpGap > 0 && top.DualBound-lowest.DualBound > d.jumpGap*math.Max(math.Abs(lowest.DualBound), 1) {
+		jump = true
+	}
+
+	var n *Node
+	if jump {
+		n = d.best.Pop()
+		d.sinceJump = 0
+	} else {
+		n = top
+		d.stack = d.stack[:len(d.stack)-1]
+	}
+	n.closed = true
+	if len(d.stack)+d.best.Size() > 4*d.size {
+		d.stack = slices.DeleteFunc(d.stack, isClosed)
+		d.best.Retain(isOpen)
+	}
+	return n
+}
+
+func (d *hybridDeque) Size() int {
+	return d.size
+}
+
+// openNodes keeps the open nodes in the deque of the node selection strategy,
+// tracking the lowest dual bound among them. The closed nodes are removed
+// lazily from the bounds heap, which is compacted once they outnumber the open
+// ones.
+type openNodes struct {
+	Deque[*Node]
+	bounds *PriorityQueue[*Node]
+}
+
+func newOpenNodes(opts SolveOptions) *openNodes {
+	return &openNodes{
+		Deque:  newNodeDeque(opts),
+		bounds: NewPriorityQueue(byDualBound),
+	}
+}
+
+func (o *openNodes) Push(n *Node) {
+	o.Deque.Push(n)
+	o.bounds.Push(n)
+}
+
+func (o *openNodes) Pop() *Node {
+	n := o.Deque.Pop()
+	n.closed = true
+	if o.bounds.Size() > 2*o.Deque.Size() {
+		o.bounds.Retain(isOpen)
+	}
+	return n
+}
+
+// lowerBound returns the lowest dual bound among the open nodes, +Inf when
+// there are none.
+func (o *openNodes) lowerBound() float64 {
+	for o.bounds.Size() > 0 && o.bounds.Peek().closed {
+		o.bounds.Pop()
+	}
+	if o.bounds.Size() == 0 {
+		return math.Inf(1)
+	}
+	return o.bounds.Peek().DualBound
+}
@@ -0,0 +1,91 @@
+package scpcs
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func nodesWithBounds(bounds ...float64) []*Node {
+	nodes := make([]*Node, len(bounds))
+	for k, b := range bounds {
+		nodes[k] = &Node{DualBound: b}
+	}
+	return nodes
+}
+
+func TestHybridDeque(t *testing.T) {
+	tests := []struct {
+		name         string
+		jumpInterval int
+		jumpGap      float64
+		bounds       []float64
+		want         []float64
+	}{
+		{"depth-first", 0, 0, []float64{1, 10, 2}, []float64{2, 10, 1}},
+		{"interval", 2, 0, []float64{5, 3, 4, 1, 2}, []float64{2, 1, 4, 3, 5}},
+		{"gap", 0, 0.5, []float64{1, 10, 2}, []float64{1, 2, 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newHybridDeque(tt.jumpInterval, tt.jumpGap)
+			for _, n := range nodesWithBounds(tt.bounds...) {
+				d.Push(n)
+			}
+			var got []float64
+			for d.Size() > 0 {
+				n := d.Pop()
+				if !n.closed {
+					t.Errorf("node %v popped open", n.DualBound)
+				}
+				got = append(got, n.DualBound)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got bounds %v, want %v", got, tt.want)
+			}
+			if n := d.Pop(); n != nil {
+				t.Errorf("empty deque popped %v", n.DualBound)
+			}
+		})
+	}
+}
+
+func TestHybridDequeCompaction(t *testing.T) {
+	d := newHybridDeque(1, 0)
+	for k := range 1000 {
+		d.Push(&Node{DualBound: float64(k % 7)})
+		if k%3 == 0 {
+			d.Pop()
+		}
+		if len(d.stack)+d.best.Size() > 4*d.size {
+			t.Fatalf("%d entries kept for %d open nodes", len(d.stack)+d.best.Size(), d.size)
+		}
+	}
+	for d.Size() > 0 {
+		d.Pop()
+		if len(d.stack)+d.best.Size() > 4*d.size {
+			t.Fatalf("%d entries kept for %d open nodes", len(d.stack)+d.best.Size(), d.size)
+		}
+	}
+}
+
+func TestOpenNodesLowerBound(t *testing.T) {
+	o := newOpenNodes(SolveOptions{NodeSelection: DepthFirst})
+	if got := o.lowerBound(); !math.IsInf(got, 1) {
+		t.Errorf("got lower bound %v with no open nodes, want +Inf", got)
+	}
+	for _, n := range nodesWithBounds(5, 3, 4, 1) {
+		o.Push(n)
+	}
+	for _, want := range []float64{1, 3, 3, 5, math.Inf(1)} {
+		if got := o.lowerBound(); got != want {
+			t.Errorf("got lower bound %v, want %v", got, want)
+		}
+		if o.Size() > 0 {
+			o.Pop()
+		}
+		if o.bounds.Size() > 2*o.Size() {
+			t.Errorf("%d bounds kept for %d open nodes", o.bounds.Size(), o.Size())
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package scpcs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// WriteTo serializes the solution in the native text format, made of a line
+// with the number of subsets of the instance and the total cost ("+Inf" when
+// there is no feasible solution) and a line with the number of selected
+// subsets followed by their 1-based indices, or in JSON.
+func (sol *Solution) WriteTo(w io.Writer, format Format) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		return enc.Encode(sol)
+	case FormatNative:
+	default:
+		return fmt.Errorf("unsupported solution format %v", format)
+	}
+
+	selected := make([]string, 0)
+	for i := range sol.Subsets.Len() {
+		if sol.Subsets.AtVec(i) > 0.5 {
+			selected = append(selected, strconv.Itoa(i+1))
+		}
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, sol.Subsets.Len(), formatCost(sol.TotalCost))
+	fmt.Fprintln(bw, len(selected), strings.Join(selected, " "))
+	return bw.Flush()
+}
+
+func (sol *Solution) parseNative(tr *tokenReader) error {
+	numSubsets, err := tr.nextSize("number of subsets")
+	if err != nil {
+		return err
+	}
+	tok, err := tr.next("total cost")
+	if err != nil {
+		return err
+	}
+	cost, err := strconv.ParseFloat(tok, 64)
+	if err != nil || math.IsNaN(cost) {
+		return tr.errorf(ErrSyntax, "invalid total cost \"%v\"", tok)
+	}
+
+	size, err := tr.nextCount("number of selected subsets")
+	if err != nil {
+		return err
+	}
+	subsets := mat.NewVecDense(numSubsets, nil)
+	for range size {
+		j, err := tr.nextIndex("subset index", numSubsets)
+		if err != nil {
+			return err
+		}
+		if subsets.AtVec(j) > 0 {
+			return tr.errorf(ErrDuplicate, "subset %d listed twice", j+1)
+		}
+		subsets.SetVec(j, 1)
+	}
+	if err := tr.expectEOF("the selected subsets"); err != nil {
+		return err
+	}
+
+	sol.Subsets = subsets
+	sol.TotalCost = cost
+	return nil
+}
+
+// ReadSolution reads a solution written by WriteTo, in either format.
+func ReadSolution(r io.Reader, name string) (*Solution, error) {
+	reader := bufio.NewReader(r)
+	sol := new(Solution)
+	data, _ := reader.Peek(64)
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		if err := json.NewDecoder(reader).Decode(sol); err != nil {
+			return nil, &ParseError{File: name, Kind: ErrSyntax, Msg: err.Error()}
+		}
+		return sol, nil
+	}
+
+	if err := sol.parseNative(newTokenReader(reader, name, maxDeclaredCount)); err != nil {
+		return nil, err
+	}
+	return sol, nil
+}
+
+func LoadSolution(filename string) (*Solution, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadSolution(file, filename)
+}
+
+// Verification is the outcome of checking a solution against an instance.
+// Indices are 0-based.
+type Verification struct {
+	Uncovered   []int
+	Conflicts   [][]int
+	Penalty     float64
+	Cost        float64
+	ClaimedCost float64
+}
+
+// Verify recomputes coverage and cost of a solution independently of the
+// algorithm that produced it.
+func (inst *Instance) Verify(sol *Solution) (*Verification, error) {
+	if sol.Subsets.Len() != inst.NumSubsets {
+		return nil, fmt.Errorf("solution has %d subsets, instance has %d", sol.Subsets.Len(), inst.NumSubsets)
+	}
+
+	v := &Verification{
+		Uncovered:   make([]int, 0),
+		Conflicts:   make([][]int, 0),
+		Cost:        inst.getCost(sol.Subsets),
+		ClaimedCost: sol.TotalCost,
+	}
+	if !inst.isFeasible(sol.Subsets) {
+		for e := range inst.NumElements {
+			if !inst.isCovered(e, sol.Subsets) {
+				v.Uncovered = append(v.Uncovered, e)
+			}
+		}
+	}
+	for _, pair := range inst.ConflictsList {
+		if sol.Subsets.AtVec(pair[0]) > 0.5 && sol.Subsets.AtVec(pair[1]) > 0.5 {
+			v.Conflicts = append(v.Conflicts, pair)
+			v.Penalty += inst.Conflicts.At(pair[0], pair[1])
+		}
+	}
+	return v, nil
+}
+
+func (v *Verification) Feasible() bool {
+	return len(v.Uncovered) == 0
+}
+
+func (v *Verification) CostMatches() bool {
+	return math.Abs(v.Cost-v.ClaimedCost) <= 1e-6*math.Max(1, math.Abs(v.Cost))
+}
+
+func (v *Verification) Valid() bool {
+	return v.Feasible() && v.CostMatches()
+}
+
+func (v *Verification) String() string {
+	s := new(strings.Builder)
+	if v.Feasible() {
+		s.WriteString("Coverage: all elements covered\n")
+	} else {
+		fmt.Fprintf(s, "Coverage: %d uncovered elements %v\n", len(v.Uncovered), v.Uncovered)
+	}
+	fmt.Fprintf(s, "Conflicts: %d incurred, penalty %f\n", len(v.Conflicts), v.Penalty)
+	for _, pair := range v.Conflicts {
+		fmt.Fprintf(s, "\t%v\n", pair)
+	}
+	fmt.Fprintf(s, "Total cost: %f\n", v.Cost)
+	if v.CostMatches() {
+		fmt.Fprintf(s, "Claimed cost: %f, matching", v.ClaimedCost)
+	} else {
+		fmt.Fprintf(s, "Claimed cost: %f, mismatch of %f", v.ClaimedCost, v.ClaimedCost-v.Cost)
+	}
+	return s.String()
+}
@@ -0,0 +1,110 @@
+package scpcs
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSolutionRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		mask      uint
+		totalCost float64
+		want      string
+	}{
+		{"selected", 1<<0 | 1<<3 | 1<<12, 12.25, "13 12.25\n3 1 4 13\n"},
+		{"empty", 0, 0, "13 0\n0 \n"},
+		{"infeasible", 1 << 5, math.Inf(1), "13 +Inf\n1 6\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sol := &Solution{Subsets: selection(13, tt.mask), TotalCost: tt.totalCost}
+			buf := new(bytes.Buffer)
+			if err := sol.WriteTo(buf, FormatNative); err != nil {
+				t.Fatalf("WriteTo: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+			got, err := ReadSolution(buf, "test")
+			if err != nil {
+				t.Fatalf("ReadSolution: %v", err)
+			}
+			if !mat.Equal(got.Subsets, sol.Subsets) || got.TotalCost != sol.TotalCost {
+				t.Errorf("read back %v, want %v", got, sol)
+			}
+		})
+	}
+}
+
+func TestReadSolutionErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		kind  error
+	}{
+		{"NaN cost", "4 NaN\n0\n", ErrSyntax},
+		{"index out of range", "4 1\n1 5\n", ErrOutOfRange},
+		{"duplicate index", "4 1\n2 3 3\n", ErrDuplicate},
+		{"missing index", "4 1\n2 3\n", ErrTruncated},
+		{"trailing data", "4 1\n1 3 4\n", ErrSyntax},
+	}
+	for _, tt := range tests {
+		_, err := ReadSolution(strings.NewReader(tt.input), "test")
+		var perr *ParseError
+		if !errors.As(err, &perr) || !errors.Is(err, tt.kind) {
+			t.Errorf("%v: got %v, want a %v error", tt.name, err, tt.kind)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	// Subset i costs i+1 but subset 12 costs 2.5; the conflicts are 0-12 with
+	// penalty 4.5, 1-2 with penalty 1 and 5-11 with penalty 2.
+	inst := parseTestInstance(t, testInstance+"conflicts 3\n1 13 4.5\n2 3 1\n6 12 2\n", LoadOptions{})
+	tests := []struct {
+		name        string
+		mask        uint
+		claimed     float64
+		uncovered   []int
+		conflicts   [][]int
+		penalty     float64
+		cost        float64
+		costMatches bool
+	}{
+		{"valid", 1<<6 | 1<<12, 9.5, []int{}, [][]int{}, 0, 9.5, true},
+		{"uncovered elements", 1<<0 | 1<<3, 5, []int{2, 3}, [][]int{}, 0, 5, true},
+		{"conflict penalty", 1<<1 | 1<<2 | 1<<6 | 1<<9, 23, []int{}, [][]int{{1, 2}}, 1, 23, true},
+		{"both", 1<<0 | 1<<12, 8, []int{1, 2}, [][]int{{0, 12}}, 4.5, 8, true},
+		{"cost mismatch", 1<<6 | 1<<12, 9, []int{}, [][]int{}, 0, 9.5, false},
+		{"penalty left out", 1<<1 | 1<<2 | 1<<6 | 1<<9, 22, []int{}, [][]int{{1, 2}}, 1, 23, false},
+	}
+	for _, tt := range tests {
+		v, err := inst.Verify(&Solution{Subsets: selection(inst.NumSubsets, tt.mask), TotalCost: tt.claimed})
+		if err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		if !slices.Equal(v.Uncovered, tt.uncovered) || v.Feasible() != (len(tt.uncovered) == 0) {
+			t.Errorf("%v: got uncovered %v, want %v", tt.name, v.Uncovered, tt.uncovered)
+		}
+		if !slices.EqualFunc(v.Conflicts, tt.conflicts, slices.Equal) || v.Penalty != tt.penalty {
+			t.Errorf("%v: got conflicts %v with penalty %v, want %v with %v", tt.name, v.Conflicts, v.Penalty, tt.conflicts, tt.penalty)
+		}
+		if v.Cost != tt.cost || v.CostMatches() != tt.costMatches {
+			t.Errorf("%v: got cost %v (matching %v), want %v (%v)", tt.name, v.Cost, v.CostMatches(), tt.cost, tt.costMatches)
+		}
+		if want := len(tt.uncovered) == 0 && tt.costMatches; v.Valid() != want {
+			t.Errorf("%v: Valid() = %v, want %v", tt.name, v.Valid(), want)
+		}
+	}
+
+	if _, err := inst.Verify(&Solution{Subsets: selection(4, 1)}); err == nil {
+		t.Errorf("verified a solution of 4 subsets against %d", inst.NumSubsets)
+	}
+}
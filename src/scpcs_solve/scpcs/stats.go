@@ -0,0 +1,199 @@
+package scpcs
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+const cliqueStartVertices = 50
+
+type Distribution struct {
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stdDev"`
+	Median float64 `json:"median"`
+}
+
+// Stats describes the structure of an instance. Element indices are 0-based.
+type Stats struct {
+	NumElements         int          `json:"elements"`
+	NumSubsets          int          `json:"subsets"`
+	Density             float64      `json:"density"`
+	RowSizes            Distribution `json:"rowSizes"`
+	ColumnSizes         Distribution `json:"columnSizes"`
+	Costs               Distribution `json:"costs"`
+	NumConflicts        int          `json:"conflicts"`
+	ConflictDensity     float64      `json:"conflictDensity"`
+	Penalties           Distribution `json:"penalties"`
+	Degrees             Distribution `json:"degrees"`
+	IsolatedSubsets     int          `json:"isolatedSubsets"`
+	Components          int          `json:"components"`
+	LargestComponent    int          `json:"largestComponent"`
+	CliqueEstimate      int          `json:"cliqueEstimate"`
+	UncoverableElements []int        `json:"uncoverableElements"`
+	SingleCoverElements []int        `json:"singleCoverElements"`
+}
+
+func newDistribution(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	mean, stdDev := stat.PopMeanStdDev(sorted, nil)
+	return Distribution{
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		Mean:   mean,
+		StdDev: stdDev,
+		Median: stat.Quantile(0.5, stat.Empirical, sorted, nil),
+	}
+}
+
+func (d Distribution) String() string {
+	return fmt.Sprintf("min %g, max %g, mean %.3f, std. dev. %.3f, median %g", d.Min, d.Max, d.Mean, d.StdDev, d.Median)
+}
+
+// conflictComponents returns the number of connected components of the
+// conflict graph and the size of the largest one.
+func conflictComponents(neighbors [][]int) (count, largest int) {
+	visited := make([]bool, len(neighbors))
+	stack := make([]int, 0)
+	for s := range neighbors {
+		if visited[s] {
+			continue
+		}
+		count++
+		size := 0
+		visited[s] = true
+		stack = append(stack, s)
+		for len(stack) > 0 {
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			size++
+			for _, u := range neighbors[v] {
+				if !visited[u] {
+					visited[u] = true
+					stack = append(stack, u)
+				}
+			}
+		}
+		largest = max(largest, size)
+	}
+	return
+}
+
+// cliqueEstimate greedily grows a clique of the conflict graph from each of the
+// highest degree subsets, adding neighbors by decreasing degree, and returns the
+// size of the largest clique found. It is a lower bound on the maximum clique.
+func (inst *Instance) cliqueEstimate(neighbors [][]int) int {
+	byDegree := make([]int, len(neighbors))
+	for i := range byDegree {
+		byDegree[i] = i
+	}
+	slices.SortFunc(byDegree, func(a, b int) int {
+		return len(neighbors[b]) - len(neighbors[a])
+	})
+
+	best := min(1, len(neighbors))
+	for _, s := range byDegree[:min(cliqueStartVertices, len(byDegree))] {
+		if len(neighbors[s])+1 <= best {
+			break
+		}
+		candidates := slices.Clone(neighbors[s])
+		slices.SortFunc(candidates, func(a, b int) int {
+			return len(neighbors[b]) - len(neighbors[a])
+		})
+		clique := []int{s}
+		for _, c := range candidates {
+			inClique := true
+			for _, v := range clique {
+				if inst.Conflicts.At(c, v) == 0 {
+					inClique = false
+					break
+				}
+			}
+			if inClique {
+				clique = append(clique, c)
+			}
+		}
+		best = max(best, len(clique))
+	}
+	return best
+}
+
+func (inst *Instance) Stats() *Stats {
+	s := &Stats{
+		NumElements:         inst.NumElements,
+		NumSubsets:          inst.NumSubsets,
+		NumConflicts:        len(inst.ConflictsList),
+		UncoverableElements: make([]int, 0),
+		SingleCoverElements: make([]int, 0),
+	}
+
+	rowSizes := make([]float64, inst.NumElements)
+	for i := range inst.NumElements {
+		rowSizes[i] = float64(len(inst.Subsets.Row(i)))
+		switch rowSizes[i] {
+		case 0:
+			s.UncoverableElements = append(s.UncoverableElements, i)
+		case 1:
+			s.SingleCoverElements = append(s.SingleCoverElements, i)
+		}
+	}
+	colSizes := make([]float64, inst.NumSubsets)
+	for j := range inst.NumSubsets {
+		colSizes[j] = float64(len(inst.Subsets.Col(j)))
+	}
+	if inst.NumElements > 0 && inst.NumSubsets > 0 {
+		s.Density = float64(inst.Subsets.NNZ()) / float64(inst.NumElements*inst.NumSubsets)
+	}
+	if inst.NumSubsets > 1 {
+		s.ConflictDensity = float64(len(inst.ConflictsList)) / float64(inst.NumSubsets*(inst.NumSubsets-1)/2)
+	}
+
+	penalties := make([]float64, len(inst.ConflictsList))
+	for k, pair := range inst.ConflictsList {
+		penalties[k] = inst.Conflicts.At(pair[0], pair[1])
+	}
+	neighbors := inst.Conflicts.neighbors
+	degrees := make([]float64, inst.NumSubsets)
+	for j, n := range neighbors {
+		degrees[j] = float64(len(n))
+		if len(n) == 0 {
+			s.IsolatedSubsets++
+		}
+	}
+
+	s.RowSizes = newDistribution(rowSizes)
+	s.ColumnSizes = newDistribution(colSizes)
+	s.Costs = newDistribution(inst.Costs.RawVector().Data)
+	s.Penalties = newDistribution(penalties)
+	s.Degrees = newDistribution(degrees)
+	s.Components, s.LargestComponent = conflictComponents(neighbors)
+	s.CliqueEstimate = inst.cliqueEstimate(neighbors)
+	return s
+}
+
+func (s *Stats) String() string {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "N. elements: %d\n", s.NumElements)
+	fmt.Fprintf(b, "N. sets: %d\n", s.NumSubsets)
+	fmt.Fprintf(b, "Density: %.4f\n", s.Density)
+	fmt.Fprintf(b, "Row sizes: %v\n", s.RowSizes)
+	fmt.Fprintf(b, "Column sizes: %v\n", s.ColumnSizes)
+	fmt.Fprintf(b, "Costs: %v\n", s.Costs)
+	fmt.Fprintf(b, "Elements covered by no subset: %d\n", len(s.UncoverableElements))
+	fmt.Fprintf(b, "Elements covered by one subset: %d\n", len(s.SingleCoverElements))
+	fmt.Fprintf(b, "Conflicts: %d (density %.4f)\n", s.NumConflicts, s.ConflictDensity)
+	fmt.Fprintf(b, "Penalties: %v\n", s.Penalties)
+	fmt.Fprintf(b, "Conflict degrees: %v\n", s.Degrees)
+	fmt.Fprintf(b, "Subsets without conflicts: %d\n", s.IsolatedSubsets)
+	fmt.Fprintf(b, "Connected components: %d (largest %d)\n", s.Components, s.LargestComponent)
+	fmt.Fprintf(b, "Largest clique estimate: %d", s.CliqueEstimate)
+	return b.String()
+}
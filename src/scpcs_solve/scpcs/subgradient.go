@@ -1,10 +1,12 @@
 package scpcs
 
 import (
+	"fmt"
 	"math"
 	"slices"
 
 	"github.com/lanl/highs"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -13,43 +15,66 @@ const (
 	subgradCoeffStep = 0.6
 )
 
-func (inst *Instance) optimizeSubgradient(lp *highs.Model, partialSol *Node) (sol *Solution, lambda *mat.VecDense, err error) {
-	lambda = mat.NewVecDense(inst.NumElements, nil)
-	if partialSol.LagrangeanMul == nil {
+// subgradient is the outcome of the subgradient optimization of a node.
+type subgradient struct {
+	// sol is the last Lagrangean solution and lambda its multipliers.
+	sol    *Solution
+	lambda *mat.VecDense
+	// average is the average of the Lagrangean solutions of all the
+	// iterations.
+	average *mat.VecDense
+	// bound is the best Lagrangean bound of all the iterations.
+	bound float64
+}
+
+// optimizeSubgradient maximizes the Lagrangean bound of a restored node, in at
+// most maxIterations iterations when it is positive.
+func (inst *Instance) optimizeSubgradient(sp *lagrangeanSubproblem, partialSol *Node, maxIterations int) (*subgradient, error) {
+	lambda := mat.NewVecDense(inst.NumElements, nil)
+	if start := partialSol.lagrangeanMul.vector(); start == nil {
 		for i := range inst.NumElements {
 			lambda.SetVec(i, 1)
 		}
 	} else {
-		lambda.CloneFromVec(partialSol.LagrangeanMul)
+		lambda.CloneFromVec(start)
 	}
 
-	best := &Solution{TotalCost: math.Inf(-1)}
+	res := &subgradient{
+		lambda:  lambda,
+		average: mat.NewVecDense(inst.NumSubsets, nil),
+		bound:   math.Inf(-1),
+	}
+	iterations := 0
 
 	noImprovementRounds := 0
 	step := subgradBaseStep
 
 	for {
-		sol, err = inst.solveLagrangeanPrimal(lp, partialSol, lambda)
+		sol, err := sp.solve(partialSol, lambda)
 		if err != nil {
-			return
+			return nil, err
 		}
+		res.sol = sol
+		res.average.AddVec(res.average, sol.Subsets)
+		iterations++
 
-		improvement := sol.TotalCost - best.TotalCost
+		improvement := sol.TotalCost - res.bound
 		if improvement > 0 {
-			best = sol
+			res.bound = sol.TotalCost
 		}
 		if improvement < 0.1 {
-			if noImprovementRounds == 5 {
-				return
-			}
 			noImprovementRounds++
 		} else {
 			noImprovementRounds = 0
 		}
+		if noImprovementRounds > 5 || iterations == maxIterations {
+			res.average.ScaleVec(1/float64(iterations), res.average)
+			return res, nil
+		}
 		step *= subgradCoeffStep
 
 		Ax := mat.NewVecDense(inst.NumElements, nil)
-		Ax.MulVec(inst.Subsets, sol.Subsets)
+		inst.Subsets.MulVecTo(Ax, sol.Subsets)
 
 		violations := mat.NewVecDense(inst.NumElements, nil)
 		for j := range inst.NumElements {
@@ -68,6 +93,11 @@ func (inst *Instance) defLagrangeanRelaxation() *highs.Model {
 
 	lp.ConstMatrix = make([]highs.Nonzero, 1)
 	lp.VarTypes = make([]highs.VariableType, numCols)
+	lp.ColLower = make([]float64, numCols)
+	lp.ColUpper = make([]float64, numCols)
+	for j := range numCols {
+		lp.ColUpper[j] = 1
+	}
 	for j := range inst.NumSubsets {
 		lp.VarTypes[j] = highs.IntegerType
 	}
@@ -76,32 +106,100 @@ func (inst *Instance) defLagrangeanRelaxation() *highs.Model {
 	return lp
 }
 
-func (inst *Instance) solveLagrangeanPrimal(lp *highs.Model, partialSol *Node, lambda *mat.VecDense) (*Solution, error) {
-	lp.ColCosts = inst.getLagrangeanCosts(lambda)
-	lp.ColLower = make([]float64, inst.NumSubsets+len(inst.ConflictsList))
-	lp.ColUpper = make([]float64, inst.NumSubsets+len(inst.ConflictsList))
-	for j := range partialSol.FixedSubsets {
-		lp.ColLower[j] = partialSol.PrimalSolution.Subsets.At(j, 0)
-		lp.ColUpper[j] = partialSol.PrimalSolution.Subsets.At(j, 0)
+// lagrangeanSubproblem is a HiGHS model of the Lagrangean subproblem that is
+// kept across the solves, which only change its objective. The HiGHS bindings
+// can not change the column bounds of a built model, so the fixings of a node
+// go through the objective as well: a subset fixed out gets a positive cost,
+// a subset fixed in a negative cost lower than anything selecting it can add,
+// compensated by the objective offset. The bindings do not accept a starting
+// solution either, so each solve starts from scratch.
+type lagrangeanSubproblem struct {
+	inst *Instance
+	raw  *highs.RawModel
+	// fixInCost is 1 plus the sum of the conflict penalties of each subset,
+	// which bounds what selecting it adds besides its Lagrangean cost, added
+	// to the shift by solve.
+	fixInCost []float64
+}
+
+func (inst *Instance) newLagrangeanSubproblem(threads int) (*lagrangeanSubproblem, error) {
+	raw, err := inst.defLagrangeanRelaxation().ToRawModel()
+	if err != nil {
+		return nil, err
 	}
-	for j := partialSol.FixedSubsets; j < len(lp.ColLower); j++ {
-		lp.ColLower[j] = 0
-		lp.ColUpper[j] = 1
+	err = raw.SetBoolOption("output_flag", false)
+	if err != nil {
+		return nil, err
+	}
+	err = raw.SetIntOption("threads", max(threads, 1))
+	if err != nil {
+		return nil, err
+	}
+	// The Lagrangean bound only holds for an optimal solution of the subproblem.
+	err = raw.SetFloat64Option("mip_rel_gap", 0)
+	if err != nil {
+		return nil, err
+	}
+	err = raw.SetFloat64Option("mip_abs_gap", 0)
+	if err != nil {
+		return nil, err
 	}
 
-	lp.Offset = mat.Sum(lambda)
-	sol, err := inst.runHighsSolver(lp)
+	sp := &lagrangeanSubproblem{
+		inst:      inst,
+		raw:       raw,
+		fixInCost: make([]float64, inst.NumSubsets),
+	}
+	for j := range inst.NumSubsets {
+		_, penalties := inst.Conflicts.Neighbors(j)
+		sp.fixInCost[j] = 1 + floats.Sum(penalties)
+	}
+	return sp, nil
+}
+
+func (sp *lagrangeanSubproblem) solve(partialSol *Node, lambda *mat.VecDense) (*Solution, error) {
+	inst := sp.inst
+	costs := inst.getLagrangeanCosts(lambda)
+	offset := mat.Sum(lambda)
+	for j := range inst.NumSubsets {
+		if !partialSol.isFixed(j) {
+			continue
+		}
+		shift := math.Abs(costs[j]) + sp.fixInCost[j]
+		if partialSol.PrimalSolution.Subsets.AtVec(j) > 0.5 {
+			costs[j] -= shift
+			offset += shift
+		} else {
+			costs[j] = shift
+		}
+	}
+
+	err := sp.raw.SetColumnCosts(costs)
+	if err != nil {
+		return nil, err
+	}
+	err = sp.raw.SetOffset(offset)
+	if err != nil {
+		return nil, err
+	}
+	solution, err := sp.raw.Solve()
 	if err != nil {
 		return nil, err
 	}
+	if solution.Status != highs.Optimal {
+		return nil, fmt.Errorf("status: %v", solution.Status.String())
+	}
 
-	return sol, nil
+	return &Solution{
+		Subsets:   mat.NewVecDense(inst.NumSubsets, solution.ColumnPrimal[:inst.NumSubsets]),
+		TotalCost: solution.Objective,
+	}, nil
 }
 
 func (inst *Instance) getLagrangeanCosts(lambda *mat.VecDense) []float64 {
 	c := mat.NewVecDense(inst.NumSubsets, nil)
 	prod := mat.NewVecDense(inst.NumSubsets, nil)
-	prod.MulVec(inst.Subsets.T(), lambda)
+	inst.Subsets.MulTransVecTo(prod, lambda)
 	c.SubVec(inst.Costs, prod)
 
 	conflictCosts := make([]float64, len(inst.ConflictsList))
@@ -114,12 +212,18 @@ func (inst *Instance) getLagrangeanCosts(lambda *mat.VecDense) []float64 {
 	return objCoeff
 }
 
-func (inst *Instance) isFeasible(selected *mat.VecDense) bool {
-	Ax := mat.NewVecDense(inst.NumElements, nil)
-	Ax.MulVec(inst.Subsets, selected)
+func (inst *Instance) isCovered(element int, selected *mat.VecDense) bool {
+	for _, j := range inst.Subsets.Row(element) {
+		if !almostEqual(selected.AtVec(j), 0) {
+			return true
+		}
+	}
+	return false
+}
 
+func (inst *Instance) isFeasible(selected *mat.VecDense) bool {
 	for i := range inst.NumElements {
-		if almostEqual(Ax.At(i, 0), 0) {
+		if !inst.isCovered(i, selected) {
 			return false
 		}
 	}
@@ -10,10 +10,12 @@ import (
 type Instance struct {
 	NumElements   int
 	NumSubsets    int
-	Subsets       *mat.Dense
+	Subsets       *Incidence
 	Costs         *mat.VecDense
-	Conflicts     *mat.Dense
+	Conflicts     *ConflictGraph
 	ConflictsList [][]int
+	ElementNames  []string
+	SubsetNames   []string
 }
 
 type Solution struct {
@@ -21,11 +23,27 @@ type Solution struct {
 	TotalCost float64
 }
 
+// Node is a node of the branch and bound tree. It stores only the subsets fixed
+// since its parent and which of them it selects; PrimalSolution, the fixed
+// subsets and their cost, is rebuilt by restore while the node is processed.
 type Node struct {
 	PrimalSolution *Solution
 	DualBound      float64
-	FixedSubsets   int
-	LagrangeanMul  *mat.VecDense
+	// Estimate is the cost of the greedy repair of the node.
+	Estimate float64
+	// FixedSubsets is the number of subsets fixed along the path to the root.
+	FixedSubsets  int
+	parent        *Node
+	fixed         []int32
+	fixings       bitset
+	fixedCost     float64
+	lagrangeanMul *multipliers
+	// branches describes the children of the node, chosen by the branching
+	// rule when the node is evaluated.
+	branches branching
+	closed   bool
+	// fixedMask marks the fixed subsets, rebuilt by restore.
+	fixedMask bitset
 }
 
 func (sol *Solution) String() string {
@@ -60,15 +78,13 @@ func (sol *Node) String() string {
 func (inst *Instance) String() string {
 	s := new(strings.Builder)
 	s.WriteString(fmt.Sprintf("N. elements: %d\n", inst.NumElements))
-	s.WriteString(fmt.Sprintf("N. sets: %d\n", inst.Subsets.RawMatrix().Cols))
+	s.WriteString(fmt.Sprintf("N. sets: %d\n", inst.NumSubsets))
 
-	for i := range inst.Subsets.RawMatrix().Cols {
+	for i := range inst.NumSubsets {
 		s.WriteString(fmt.Sprintf("Cost: %f, ", inst.Costs.At(i, 0)))
 		s.WriteString("Elements: ")
-		for e := range inst.NumElements {
-			if inst.Subsets.At(e, i) == 1 {
-				s.WriteString(fmt.Sprintf("%d ", e))
-			}
+		for _, e := range inst.Subsets.Col(i) {
+			s.WriteString(fmt.Sprintf("%d ", e))
 		}
 		s.WriteRune('\n')
 	}
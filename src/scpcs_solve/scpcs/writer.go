@@ -0,0 +1,116 @@
+package scpcs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const orLibLineLength = 12
+
+func formatCost(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// WriteTo serializes the instance in the given format. When withConflicts is
+// set the conflicts are written explicitly, so that reading the output back
+// does not derive them again from the intersections.
+func (inst *Instance) WriteTo(w io.Writer, format Format, withConflicts bool) error {
+	if format == FormatJSON {
+		ji := inst.toJSON()
+		if !withConflicts {
+			ji.Conflicts = nil
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(ji)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%d %d\n", inst.NumElements, inst.NumSubsets)
+	switch format {
+	case FormatNative:
+		inst.writeCosts(bw, inst.NumSubsets)
+		inst.writeNativeRows(bw)
+	case FormatORLib:
+		inst.writeCosts(bw, orLibLineLength)
+		inst.writeORLibRows(bw)
+	case FormatRail:
+		inst.writeColumns(bw)
+	default:
+		return fmt.Errorf("unsupported instance format %v", format)
+	}
+	if withConflicts {
+		inst.writeConflicts(bw)
+	}
+	return bw.Flush()
+}
+
+// writeList writes the values wrapping the line every lineLength values.
+func writeList(w *bufio.Writer, values []string, lineLength int) {
+	for k, v := range values {
+		if k > 0 {
+			if k%lineLength == 0 {
+				w.WriteRune('\n')
+			} else {
+				w.WriteRune(' ')
+			}
+		}
+		w.WriteString(v)
+	}
+	w.WriteRune('\n')
+}
+
+func (inst *Instance) writeCosts(w *bufio.Writer, lineLength int) {
+	costs := make([]string, inst.NumSubsets)
+	for j := range inst.NumSubsets {
+		costs[j] = formatCost(inst.Costs.AtVec(j))
+	}
+	writeList(w, costs, max(1, lineLength))
+}
+
+func (inst *Instance) rowIndices(i int) []string {
+	row := make([]string, 0, len(inst.Subsets.Row(i)))
+	for _, j := range inst.Subsets.Row(i) {
+		row = append(row, strconv.Itoa(j+1))
+	}
+	return row
+}
+
+func (inst *Instance) writeNativeRows(w *bufio.Writer) {
+	for i := range inst.NumElements {
+		row := inst.rowIndices(i)
+		row = append([]string{strconv.Itoa(len(row))}, row...)
+		writeList(w, row, len(row))
+	}
+}
+
+func (inst *Instance) writeORLibRows(w *bufio.Writer) {
+	for i := range inst.NumElements {
+		row := inst.rowIndices(i)
+		fmt.Fprintln(w, len(row))
+		if len(row) > 0 {
+			writeList(w, row, orLibLineLength)
+		}
+	}
+}
+
+func (inst *Instance) writeColumns(w *bufio.Writer) {
+	for j := range inst.NumSubsets {
+		col := []string{formatCost(inst.Costs.AtVec(j)), ""}
+		for _, i := range inst.Subsets.Col(j) {
+			col = append(col, strconv.Itoa(i+1))
+		}
+		col[1] = strconv.Itoa(len(col) - 2)
+		writeList(w, col, len(col))
+	}
+}
+
+func (inst *Instance) writeConflicts(w *bufio.Writer) {
+	fmt.Fprintln(w, "conflicts", len(inst.ConflictsList))
+	for _, pair := range inst.ConflictsList {
+		fmt.Fprintln(w, pair[0]+1, pair[1]+1, formatCost(inst.Conflicts.At(pair[0], pair[1])))
+	}
+}
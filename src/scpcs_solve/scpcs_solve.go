@@ -4,25 +4,87 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"runtime"
 	"scp_with_conflicts/src/scpcs_solve/scpcs"
 	"strings"
 )
 
+func writeSolution(outDir, instPath, algorithm string, sol *scpcs.Solution) error {
+	name := strings.TrimSuffix(filepath.Base(instPath), filepath.Ext(instPath))
+	if instPath == "-" {
+		name = "stdin"
+	}
+	path := filepath.Join(outDir, fmt.Sprintf("%v.%v.sol", name, algorithm))
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = sol.WriteTo(file, scpcs.FormatNative)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// Only a partial regular file is removed, not a device or a pipe.
+		if fi, statErr := os.Stat(path); statErr == nil && fi.Mode().IsRegular() {
+			os.Remove(path)
+		}
+	}
+	return err
+}
+
 func main() {
 	var solveHighs, solveLagrangean bool
-	var conflictThreshold int
 	var paths []string
+	var outDir string
+	var solveOpts scpcs.SolveOptions
+	var threads int
 
-	flag.Func("inst", "a list of instance file paths, separated by a whitespace", func(s string) error {
+	flag.Func("inst", "a list of instance file paths, separated by a whitespace, - for the standard input", func(s string) error {
 		paths = strings.Fields(s)
 		return nil
 	})
+	loadOpts := scpcs.InstanceFlags(flag.CommandLine)
 	flag.BoolVar(&solveHighs, "highs", false, "Solve the problem using the HiGHS solver")
 	flag.BoolVar(&solveLagrangean, "lagrangean", false, "Solve with branch and bound using lagrangean relaxation for dual")
-	flag.IntVar(&conflictThreshold, "threshold", 0, "Define the minimum intersection size between subsets to be considered in conflict")
+	flag.Func("greedy-score", "the score of the subsets in the greedy repair: ratio (cost per newly covered element), log or sqrt (default ratio)", func(s string) (err error) {
+		solveOpts.GreedyScore, err = scpcs.ParseGreedyScore(s)
+		return
+	})
+	flag.Func("node-selection", "the order in which the branch and bound explores the nodes: depth-first, best-first, breadth-first, best-estimate or hybrid (default depth-first)", func(s string) (err error) {
+		solveOpts.NodeSelection, err = scpcs.ParseNodeSelection(s)
+		return
+	})
+	flag.Func("branching", "the rule choosing the subsets fixed in the children of a node: fractional (closest to 1/2 in the average Lagrangean solution), coverage (largest multipliers of the uncovered elements), reduced-cost, conflict-degree, index, strong (largest bound increases over a few subgradient iterations) reliability (strong until the pseudo-costs are reliable), element-candidates or element-multiplier (a child per subset covering the uncovered element with the fewest such subsets or the largest multiplier) or conflict-pair (not i, i but not j, both i and j for a conflict selected by the Lagrangean solutions) (default fractional)", func(s string) (err error) {
+		solveOpts.BranchingRule, err = scpcs.ParseBranchingRule(s)
+		return
+	})
+	flag.IntVar(&solveOpts.BranchingFactor, "branching-factor", 0, "The largest number of children of a branch and bound node, 2 for binary branching, 0 for the number of threads")
+	flag.IntVar(&solveOpts.JumpInterval, "jump-interval", 100, "The number of nodes after which the hybrid node selection jumps to the node with the lowest bound, 0 to disable")
+	flag.Float64Var(&solveOpts.JumpGap, "jump-gap", 0.05, "The relative gap from the lowest bound beyond which the hybrid node selection jumps to the node with the lowest bound, 0 to disable")
+	flag.IntVar(&threads, "threads", runtime.NumCPU(), "The maximum number of threads used to load the instances and to solve them")
+	flag.StringVar(&outDir, "out", "", "The directory where to write the solution files, named <instance>.<algorithm>.sol")
 
 	flag.Parse()
 
+	if threads < 1 {
+		fmt.Fprintln(os.Stderr, "The number of threads must be positive")
+		os.Exit(1)
+	}
+	if solveOpts.BranchingFactor < 0 || solveOpts.BranchingFactor == 1 {
+		fmt.Fprintln(os.Stderr, "The branching factor must be at least 2, or 0 for the number of threads")
+		os.Exit(1)
+	}
+	solveOpts.Threads = threads
+	loadOpts.Threads = threads
+	// HiGHS shares its threads among the solves of the process, so their
+	// number is chosen once: when the branch and bound runs its node
+	// evaluations in parallel each of them solves with a single thread.
+	solveOpts.HighsThreads = threads
+	if solveLagrangean {
+		solveOpts.HighsThreads = 1
+	}
+
 	if len(paths) == 0 {
 		fmt.Fprintln(os.Stderr, "Must specify at least a path")
 		os.Exit(1)
@@ -33,7 +95,7 @@ func main() {
 	}
 
 	for _, p := range paths {
-		inst, err := scpcs.LoadInstance(p, conflictThreshold)
+		inst, err := scpcs.LoadInstance(p, *loadOpts)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error for instance \"%v\": %v. Skipping...\n", p, err)
 			continue
@@ -41,20 +103,30 @@ func main() {
 
 		if solveHighs {
 			fmt.Printf("Solving %v...\n", p)
-			sol, err := inst.Solve()
+			sol, err := inst.Solve(solveOpts)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "An error occured while solving with HiGHS instance \"%v\": %v\n", p, err)
 			} else {
 				fmt.Printf("Instance %v:\n%v\n", p, sol)
+				if outDir != "" {
+					if err := writeSolution(outDir, p, "highs", sol); err != nil {
+						fmt.Fprintf(os.Stderr, "Error while writing the solution of \"%v\": %v\n", p, err)
+					}
+				}
 			}
 		}
 		if solveLagrangean {
 			fmt.Printf("Solving %v...\n", p)
-			sol, err := inst.SolveWithLagrangeanRelaxation()
+			sol, err := inst.SolveWithLagrangeanRelaxation(solveOpts)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "An error occured while solving with B&B instance \"%v\": %v\n", p, err)
 			} else {
 				fmt.Printf("Instance %v:\n%v\n", p, sol)
+				if outDir != "" {
+					if err := writeSolution(outDir, p, "lagrangean", sol); err != nil {
+						fmt.Fprintf(os.Stderr, "Error while writing the solution of \"%v\": %v\n", p, err)
+					}
+				}
 			}
 		}
 		fmt.Println()
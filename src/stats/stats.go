@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"scp_with_conflicts/src/scpcs_solve/scpcs"
+	"strings"
+)
+
+func main() {
+	var paths []string
+	var asJSON bool
+
+	flag.Func("inst", "a list of instance file paths, separated by a whitespace, - for the standard input", func(s string) error {
+		paths = strings.Fields(s)
+		return nil
+	})
+	loadOpts := scpcs.InstanceFlags(flag.CommandLine)
+	flag.BoolVar(&asJSON, "json", false, "Print the statistics as JSON, one object per line")
+
+	flag.Parse()
+
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, "Must specify at least a path")
+		os.Exit(1)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	for _, p := range paths {
+		inst, err := scpcs.LoadInstance(p, *loadOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error for instance \"%v\": %v. Skipping...\n", p, err)
+			continue
+		}
+
+		stats := inst.Stats()
+		if asJSON {
+			enc.Encode(struct {
+				Instance string `json:"instance"`
+				*scpcs.Stats
+			}{p, stats})
+		} else {
+			fmt.Printf("Instance %v:\n%v\n\n", p, stats)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"scp_with_conflicts/src/scpcs_solve/scpcs"
+)
+
+func main() {
+	var instPath, solPath string
+
+	flag.StringVar(&instPath, "inst", "", "The instance file, - for the standard input")
+	flag.StringVar(&solPath, "sol", "", "The solution file")
+	loadOpts := scpcs.InstanceFlags(flag.CommandLine)
+
+	flag.Parse()
+
+	if instPath == "" || solPath == "" {
+		fmt.Fprintln(os.Stderr, "Must specify the instance and the solution files")
+		os.Exit(1)
+	}
+
+	inst, err := scpcs.LoadInstance(instPath, *loadOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error for instance \"%v\": %v\n", instPath, err)
+		os.Exit(1)
+	}
+	sol, err := scpcs.LoadSolution(solPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error for solution \"%v\": %v\n", solPath, err)
+		os.Exit(1)
+	}
+
+	v, err := inst.Verify(sol)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while verifying \"%v\": %v\n", solPath, err)
+		os.Exit(1)
+	}
+	fmt.Println(v)
+	if !v.Valid() {
+		os.Exit(2)
+	}
+}